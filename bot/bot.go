@@ -0,0 +1,194 @@
+// Package bot provides computer opponents. A bot sees the same redacted
+// game view as a human player and acts through the public GameService API.
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/dfturn/alns/models"
+	"github.com/dfturn/alns/service"
+)
+
+// Difficulty levels accepted in RoomOptions.Level
+const (
+	LevelRandom    = 1 // Plays a random legal move
+	LevelHeuristic = 2 // Greedily maximizes board strength
+	LevelSearch    = 3 // Determinized Monte Carlo tree search
+
+	DefaultLevel = LevelHeuristic
+)
+
+// ErrUnknownLevel is returned for a difficulty level there is no bot for
+var ErrUnknownLevel = errors.New("unknown bot level")
+
+// moveDelay keeps bot moves from landing faster than a person can follow
+const moveDelay = 600 * time.Millisecond
+
+// Agent decides the moves for one player
+type Agent interface {
+	// Name is the player name the agent joins rooms with
+	Name() string
+	// ChooseAction picks the next action for the viewer of a game. It is
+	// only called when the viewer is the player who must act.
+	ChooseAction(view *models.GameView) (models.Action, error)
+}
+
+// New creates an agent for a difficulty level; 0 selects DefaultLevel.
+// Agents with the same seed make the same choices.
+func New(level int, seed int64) (Agent, error) {
+	if level == 0 {
+		level = DefaultLevel
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	switch level {
+	case LevelHeuristic:
+		return NewHeuristic(rng), nil
+	case LevelRandom:
+		return NewRandom(rng), nil
+	case LevelSearch:
+		return NewMCTS(rng, defaultIterations), nil
+	}
+	return nil, fmt.Errorf("%w %d", ErrUnknownLevel, level)
+}
+
+// Join seats a bot of the room's level as its second player and starts
+// playing the game
+func Join(svc *service.GameService, roomID string) (*models.Room, error) {
+	room, err := svc.GetRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := New(room.Options.Level, time.Now().UnixNano())
+	if err != nil {
+		return nil, err
+	}
+
+	room, game, err := svc.SeatBot(roomID, agent.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	go Run(svc, game.ID, room.Player2.ID, agent)
+	return room, nil
+}
+
+// Resume restarts the bots of every game in progress, e.g. after the
+// server restarted with a persistent store
+func Resume(svc *service.GameService) error {
+	rooms, err := svc.ListRooms()
+	if err != nil {
+		return err
+	}
+
+	for _, room := range rooms {
+		if room.Options.Opponent != models.OpponentBot || room.Status != models.RoomStatusPlaying {
+			continue
+		}
+		agent, err := New(room.Options.Level, time.Now().UnixNano())
+		if err != nil {
+			return err
+		}
+		go Run(svc, room.GameID, room.Player2.ID, agent)
+	}
+	return nil
+}
+
+// Run plays playerID's side of a game until its updates stop
+func Run(svc *service.GameService, gameID, playerID string, agent Agent) error {
+	views, cancel, err := svc.Subscribe(gameID, playerID, 0)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for view := range views {
+		if view.Phase == models.PhaseScoring && view.BattleWinnerID == "" {
+			err := confirmScores(svc, gameID, playerID, view)
+			if err != nil && !errors.Is(err, service.ErrVersionConflict) {
+				log.Printf("bot %s in game %s: %v", agent.Name(), gameID, err)
+			}
+			continue
+		}
+		if actingPlayerID(view) != playerID {
+			continue
+		}
+
+		time.Sleep(moveDelay)
+		action, err := agent.ChooseAction(view)
+		if err == nil {
+			err = Submit(svc, gameID, view.Version, action)
+		}
+		if errors.Is(err, service.ErrVersionConflict) {
+			// The game moved on; the next view tells us what to do
+			continue
+		}
+		if err != nil {
+			log.Printf("bot %s in game %s: %v", agent.Name(), gameID, err)
+
+			// Fall back to any legal move so the game does not stall
+			action, err = randomAction(determinize(view, rng), playerID, rng)
+			if err == nil {
+				err = Submit(svc, gameID, view.Version, action)
+			}
+			if err != nil && !errors.Is(err, service.ErrVersionConflict) {
+				log.Printf("bot %s in game %s is stuck: %v", agent.Name(), gameID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// actingPlayerID returns the player who must act next in a view
+func actingPlayerID(view *models.GameView) string {
+	if view.Phase != models.PhasePlaying {
+		return ""
+	}
+	if len(view.PendingAbilities) > 0 {
+		return view.PendingAbilities[len(view.PendingAbilities)-1].PlayerID
+	}
+	return view.CurrentPlayerID
+}
+
+// confirmScores has a bot submit the server's totals for a battle, and
+// accept them if the other player disputes them
+func confirmScores(svc *service.GameService, gameID, playerID string, view *models.GameView) error {
+	if _, submitted := view.ScoreSubmissions[playerID]; !submitted {
+		totals := make(map[models.TheaterType]models.TheaterScore, len(view.TheaterScores))
+		for theater, score := range view.TheaterScores {
+			totals[theater] = *score
+		}
+		_, err := svc.UpdateTheaterScores(gameID, playerID, view.Version, totals)
+		return err
+	}
+
+	if len(view.DisputedTheaters) > 0 {
+		_, err := svc.AcceptServerScores(gameID, playerID, view.Version)
+		return err
+	}
+	return nil
+}
+
+// Submit performs an action through the GameService method for its type
+func Submit(svc *service.GameService, gameID string, version int, action models.Action) error {
+	var err error
+	switch action.Type {
+	case models.ActionPlayCard:
+		_, err = svc.PlayCard(gameID, action.PlayerID, version, action.CardID, action.Theater, action.FaceUp)
+	case models.ActionResolveAbility:
+		_, err = svc.ResolveAbility(gameID, action.PlayerID, version, *action.Choice)
+	case models.ActionWithdraw:
+		_, err = svc.Withdraw(gameID, action.PlayerID, version)
+	case models.ActionEndTurn:
+		_, err = svc.EndTurn(gameID, action.PlayerID, version)
+	default:
+		err = fmt.Errorf("bot cannot perform %s", action.Type)
+	}
+	return err
+}
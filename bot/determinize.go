@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"math/rand"
+
+	"github.com/dfturn/alns/models"
+)
+
+// determinize builds a complete game state consistent with what the viewer
+// of a view can see. Cards the viewer cannot identify (the opponent's hand
+// and face-down cards, the deck and the trash) are dealt at random from the
+// cards that are not visible anywhere.
+func determinize(view *models.GameView, rng *rand.Rand) *models.GameState {
+	game := &models.GameState{
+		ID:                view.ID,
+		RoomID:            view.RoomID,
+		Version:           view.Version,
+		Player1:           models.Player{ID: view.Player1.ID, Name: view.Player1.Name, Score: view.Player1.Score},
+		Player2:           models.Player{ID: view.Player2.ID, Name: view.Player2.Name, Score: view.Player2.Score},
+		TheaterOrder:      append([]models.TheaterType(nil), view.TheaterOrder...),
+		Theaters:          make(map[models.TheaterType]*models.Theater, len(view.Theaters)),
+		CurrentPlayerID:   view.CurrentPlayerID,
+		Phase:             view.Phase,
+		BattleNumber:      view.BattleNumber,
+		FirstPlayerID:     view.FirstPlayerID,
+		WithdrewPlayerID:  view.WithdrewPlayerID,
+		BattleWinnerID:    view.BattleWinnerID,
+		AirDropPlayerID:   view.AirDropPlayerID,
+		AirDropReady:      view.AirDropReady,
+		ExtraTurnPlayerID: view.ExtraTurnPlayerID,
+		Mode:              view.Mode,
+	}
+
+	// Everything the viewer can see is taken out of the unknown pool
+	known := make(map[int]bool)
+	for _, player := range []models.PlayerView{view.Player1, view.Player2} {
+		for _, card := range player.Hand {
+			known[card.ID] = true
+		}
+	}
+	for t, theater := range view.Theaters {
+		game.Theaters[t] = &models.Theater{Type: theater.Type, Cards: append([]models.PlayedCard{}, theater.Cards...)}
+		for _, pc := range theater.Cards {
+			if pc.Card.ID != 0 {
+				known[pc.Card.ID] = true
+			}
+		}
+	}
+	var revealed *models.Card
+	for _, pending := range view.PendingAbilities {
+		if pending.RevealedCard != nil {
+			card := *pending.RevealedCard
+			revealed = &card
+			known[card.ID] = true
+		}
+	}
+
+	var pool []models.Card
+	for _, card := range models.AllCards() {
+		if !known[card.ID] {
+			pool = append(pool, card)
+		}
+	}
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	deal := func(n int) []models.Card {
+		if n > len(pool) {
+			n = len(pool)
+		}
+		cards := append([]models.Card{}, pool[:n]...)
+		pool = pool[n:]
+		return cards
+	}
+
+	for _, t := range game.TheaterOrder {
+		cards := game.Theaters[t].Cards
+		for i := range cards {
+			if cards[i].Card.ID == 0 {
+				if dealt := deal(1); len(dealt) == 1 {
+					cards[i].Card = dealt[0]
+				}
+			}
+		}
+	}
+
+	game.Player1.Hand = append([]models.Card{}, view.Player1.Hand...)
+	if len(game.Player1.Hand) < view.Player1.HandCount {
+		game.Player1.Hand = deal(view.Player1.HandCount)
+	}
+	game.Player2.Hand = append([]models.Card{}, view.Player2.Hand...)
+	if len(game.Player2.Hand) < view.Player2.HandCount {
+		game.Player2.Hand = deal(view.Player2.HandCount)
+	}
+
+	deckCount := view.DeckCount
+	if revealed != nil {
+		game.Deck = append(game.Deck, *revealed)
+		deckCount--
+	}
+	game.Deck = append(game.Deck, deal(deckCount)...)
+	game.Trash = deal(view.TrashCount)
+
+	if view.TheaterScores != nil {
+		game.TheaterScores = make(map[models.TheaterType]*models.TheaterScore, len(view.TheaterScores))
+		for t, score := range view.TheaterScores {
+			scoreCopy := *score
+			game.TheaterScores[t] = &scoreCopy
+		}
+	}
+
+	// The opponent's Reinforce looks at whatever is now on top of the deck
+	for _, pending := range view.PendingAbilities {
+		if pending.Ability == models.AbilityReinforce && pending.RevealedCard == nil && len(game.Deck) > 0 {
+			top := game.Deck[0]
+			pending.RevealedCard = &top
+		}
+		game.PendingAbilities = append(game.PendingAbilities, pending)
+	}
+
+	return game
+}
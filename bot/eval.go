@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"math"
+
+	"github.com/dfturn/alns/models"
+	"github.com/dfturn/alns/service"
+)
+
+// battleVP is what winning a battle outright is worth
+const battleVP = 6
+
+// battleOver reports whether the battle has been decided
+func battleOver(game *models.GameState) bool {
+	return game.Phase != models.PhasePlaying
+}
+
+// outcome scores a finished battle for playerID from -1 (lost 6 VP) to 1
+// (won 6 VP), relative to the scores at the start of the search
+func outcome(game *models.GameState, playerID string, startScores [2]int) float64 {
+	if game.WithdrewPlayerID != "" {
+		gain1 := game.Player1.Score - startScores[0]
+		gain2 := game.Player2.Score - startScores[1]
+		if playerID == game.Player1.ID {
+			return float64(gain1-gain2) / battleVP
+		}
+		return float64(gain2-gain1) / battleVP
+	}
+
+	held := 0
+	for _, score := range service.ComputeTheaterScores(game) {
+		if service.TheaterController(game, score) == playerID {
+			held++
+		}
+	}
+	if held >= 2 {
+		return 1
+	}
+	return -1
+}
+
+// evaluate estimates how good a position is for playerID, from -1 to 1
+func evaluate(game *models.GameState, playerID string, startScores [2]int) float64 {
+	if battleOver(game) {
+		return outcome(game, playerID, startScores)
+	}
+
+	// Sum in board order so the same position always gets the same value
+	scores := service.ComputeTheaterScores(game)
+	total := 0.0
+	for _, t := range game.TheaterOrder {
+		score := scores[t]
+		margin := float64(score.Player1Total - score.Player2Total)
+		if playerID != game.Player1.ID {
+			margin = -margin
+		}
+		// A tie is worth a little to the first player
+		if game.FirstPlayerID == playerID {
+			margin += 0.5
+		} else {
+			margin -= 0.5
+		}
+		total += math.Tanh(margin / 4)
+	}
+
+	// Cards still in hand can swing theaters later
+	mine, theirs := len(game.Player1.Hand), len(game.Player2.Hand)
+	if playerID != game.Player1.ID {
+		mine, theirs = theirs, mine
+	}
+	total += 0.15 * float64(mine-theirs)
+
+	return 0.8 * math.Tanh(total)
+}
+
+func scoresOf(game *models.GameState) [2]int {
+	return [2]int{game.Player1.Score, game.Player2.Score}
+}
@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"math/rand"
+
+	"github.com/dfturn/alns/models"
+	"github.com/dfturn/alns/service"
+)
+
+// heuristicSamples is how many guesses at the hidden cards each move is
+// evaluated against
+const heuristicSamples = 8
+
+// Heuristic plays the move that leaves the best-looking board, averaged
+// over several guesses at the hidden cards. It withdraws when staying in
+// looks worse than the VP a withdrawal would give away.
+type Heuristic struct {
+	rng *rand.Rand
+}
+
+// NewHeuristic creates a heuristic agent
+func NewHeuristic(rng *rand.Rand) *Heuristic {
+	return &Heuristic{rng: rng}
+}
+
+// Name implements Agent
+func (b *Heuristic) Name() string {
+	return "Bot (heuristic)"
+}
+
+// ChooseAction implements Agent
+func (b *Heuristic) ChooseAction(view *models.GameView) (models.Action, error) {
+	playerID := view.ViewerID
+	samples := make([]*models.GameState, heuristicSamples)
+	for i := range samples {
+		samples[i] = determinize(view, b.rng)
+	}
+
+	actions := service.LegalActions(samples[0], playerID)
+	if len(actions) == 0 {
+		return models.Action{}, errNoLegalAction
+	}
+
+	startScores := scoresOf(samples[0])
+	best, bestValue := actions[0], -2.0
+	for _, action := range actions {
+		value := 0.0
+		for _, sample := range samples {
+			game := service.CloneGame(sample)
+			if err := service.ApplyAction(game, action); err != nil {
+				// Only legal with the hidden cards of another sample
+				value -= 2
+				continue
+			}
+			value += evaluate(game, playerID, startScores)
+		}
+		value /= float64(len(samples))
+		if value > bestValue {
+			best, bestValue = action, value
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/dfturn/alns/models"
+	"github.com/dfturn/alns/service"
+)
+
+const (
+	// defaultIterations is the search budget per move for LevelSearch
+	defaultIterations = 2000
+	// rolloutLimit caps the moves played out after leaving the tree
+	rolloutLimit = 60
+	// exploration is the UCB1 exploration constant
+	exploration = 0.7
+)
+
+// MCTS searches with information-set Monte Carlo tree search: every
+// iteration deals a fresh guess at the hidden cards, walks the shared tree
+// using only moves that are legal in that guess, and plays the battle out
+// at random. Moves are scored by how the battle ends.
+type MCTS struct {
+	rng        *rand.Rand
+	iterations int
+}
+
+// NewMCTS creates a search agent that runs iterations playouts per move
+func NewMCTS(rng *rand.Rand, iterations int) *MCTS {
+	return &MCTS{rng: rng, iterations: iterations}
+}
+
+// Name implements Agent
+func (b *MCTS) Name() string {
+	return "Bot (search)"
+}
+
+// mctsNode is a move in the search tree. Rewards are from the point of
+// view of the player who made the move.
+type mctsNode struct {
+	parent   *mctsNode
+	action   models.Action
+	key      string
+	playerID string
+	children []*mctsNode
+	visits   int
+	avail    int
+	reward   float64
+}
+
+func (n *mctsNode) child(key string) *mctsNode {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// ucb scores a child for selection
+func (n *mctsNode) ucb() float64 {
+	return n.reward/float64(n.visits) + exploration*math.Sqrt(math.Log(float64(n.avail))/float64(n.visits))
+}
+
+// ChooseAction implements Agent
+func (b *MCTS) ChooseAction(view *models.GameView) (models.Action, error) {
+	root := &mctsNode{}
+	startScores := [2]int{view.Player1.Score, view.Player2.Score}
+
+	for i := 0; i < b.iterations; i++ {
+		game := determinize(view, b.rng)
+		node := root
+
+		// Selection and expansion
+		for !battleOver(game) {
+			actor := service.ActingPlayerID(game)
+			legal := service.LegalActions(game, actor)
+			if len(legal) == 0 {
+				break
+			}
+
+			var untried []models.Action
+			var candidates []*mctsNode
+			for _, action := range legal {
+				if c := node.child(actionKey(action)); c != nil {
+					c.avail++
+					candidates = append(candidates, c)
+				} else {
+					untried = append(untried, action)
+				}
+			}
+
+			if len(untried) > 0 {
+				action := untried[b.rng.Intn(len(untried))]
+				if err := service.ApplyAction(game, action); err != nil {
+					break
+				}
+				next := &mctsNode{parent: node, action: action, key: actionKey(action), playerID: actor, avail: 1}
+				node.children = append(node.children, next)
+				node = next
+				break
+			}
+
+			next := candidates[0]
+			for _, c := range candidates[1:] {
+				if c.ucb() > next.ucb() {
+					next = c
+				}
+			}
+			if err := service.ApplyAction(game, next.action); err != nil {
+				break
+			}
+			node = next
+		}
+
+		// Rollout
+		for steps := 0; steps < rolloutLimit && !battleOver(game); steps++ {
+			action, err := randomAction(game, service.ActingPlayerID(game), b.rng)
+			if err != nil || service.ApplyAction(game, action) != nil {
+				break
+			}
+		}
+
+		// Backpropagation
+		for n := node; n != nil; n = n.parent {
+			n.visits++
+			if n.playerID != "" {
+				n.reward += evaluate(game, n.playerID, startScores)
+			}
+		}
+	}
+
+	if len(root.children) == 0 {
+		return models.Action{}, errNoLegalAction
+	}
+	best := root.children[0]
+	for _, c := range root.children[1:] {
+		if c.visits > best.visits {
+			best = c
+		}
+	}
+	return best.action, nil
+}
+
+// actionKey identifies an action across different guesses at the hidden cards
+func actionKey(action models.Action) string {
+	key := fmt.Sprintf("%s/%d/%s/%t", action.Type, action.CardID, action.Theater, action.FaceUp)
+	if c := action.Choice; c != nil {
+		key += fmt.Sprintf("/%t/%d/%s/%s/%s", c.Skip, c.CardID, c.Theater, c.OwnerID, c.ToTheater)
+	}
+	return key
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/dfturn/alns/models"
+	"github.com/dfturn/alns/service"
+)
+
+var errNoLegalAction = errors.New("no legal action")
+
+// Random plays a uniformly random legal move. It never withdraws.
+type Random struct {
+	rng *rand.Rand
+}
+
+// NewRandom creates a random agent
+func NewRandom(rng *rand.Rand) *Random {
+	return &Random{rng: rng}
+}
+
+// Name implements Agent
+func (b *Random) Name() string {
+	return "Bot (random)"
+}
+
+// ChooseAction implements Agent
+func (b *Random) ChooseAction(view *models.GameView) (models.Action, error) {
+	return randomAction(determinize(view, b.rng), view.ViewerID, b.rng)
+}
+
+// randomAction picks a random legal move other than withdrawing
+func randomAction(game *models.GameState, playerID string, rng *rand.Rand) (models.Action, error) {
+	actions := withoutWithdraw(service.LegalActions(game, playerID))
+	if len(actions) == 0 {
+		return models.Action{}, errNoLegalAction
+	}
+	return actions[rng.Intn(len(actions))], nil
+}
+
+func withoutWithdraw(actions []models.Action) []models.Action {
+	kept := actions[:0:0]
+	for _, action := range actions {
+		if action.Type != models.ActionWithdraw {
+			kept = append(kept, action)
+		}
+	}
+	return kept
+}
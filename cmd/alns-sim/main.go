@@ -0,0 +1,184 @@
+// Command alns-sim plays bot agents against each other in-process, without
+// the HTTP server, and reports how they fared. Runs with the same flags
+// produce the same report.
+//
+//	go run ./cmd/alns-sim -games 2000 -a heuristic -b random -seed 1
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/dfturn/alns/bot"
+)
+
+// agents maps the names accepted on the command line to agent constructors
+var agents = map[string]func(rng *rand.Rand, iterations int) bot.Agent{
+	"random": func(rng *rand.Rand, _ int) bot.Agent {
+		return bot.NewRandom(rng)
+	},
+	"heuristic": func(rng *rand.Rand, _ int) bot.Agent {
+		return bot.NewHeuristic(rng)
+	},
+	"search": func(rng *rand.Rand, iterations int) bot.Agent {
+		return bot.NewMCTS(rng, iterations)
+	},
+}
+
+func main() {
+	games := flag.Int("games", 1000, "number of games to play")
+	seed := flag.Int64("seed", 1, "seed for deals, first players and agent choices")
+	agentA := flag.String("a", "heuristic", "first agent: "+agentNames())
+	agentB := flag.String("b", "random", "second agent: "+agentNames())
+	iterations := flag.Int("iterations", 300, "playouts per move for the search agent")
+	maxActions := flag.Int("max-actions", 1000, "abandon a game after this many actions")
+	workers := flag.Int("workers", runtime.NumCPU(), "games played in parallel")
+	flag.Parse()
+
+	names := [2]string{*agentA, *agentB}
+	for _, name := range names {
+		if agents[name] == nil {
+			log.Fatalf("Unknown agent %q; choose from %s", name, agentNames())
+		}
+	}
+
+	// Every game gets its own seed so any game can be replayed on its own
+	seeds := make([]int64, *games)
+	rng := rand.New(rand.NewSource(*seed))
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+
+	results := make([]*gameResult, *games)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// Agents swap seats every game so neither keeps the first seat
+				seating := [2]int{0, 1}
+				if i%2 == 1 {
+					seating = [2]int{1, 0}
+				}
+
+				var players [2]bot.Agent
+				for seat, agent := range seating {
+					players[seat] = agents[names[agent]](rand.New(rand.NewSource(seeds[i]+int64(seat)+1)), *iterations)
+				}
+
+				result, err := playGame(seeds[i], players, *maxActions)
+				if err != nil {
+					log.Printf("Game %d (seed %d) abandoned: %v", i, seeds[i], err)
+					continue
+				}
+				results[i] = result.reseat(seating)
+			}
+		}()
+	}
+	for i := range seeds {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	report(os.Stdout, names, results)
+}
+
+func agentNames() string {
+	names := make([]string, 0, len(agents))
+	for name := range agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// reseat reorders a result indexed by seat into one indexed by agent
+func (r *gameResult) reseat(seating [2]int) *gameResult {
+	reseated := &gameResult{winner: seating[r.winner], actions: r.actions}
+	for _, battle := range r.battles {
+		b := battleResult{withdrawal: battle.withdrawal}
+		for seat, agent := range seating {
+			b.vp[agent] = battle.vp[seat]
+		}
+		reseated.battles = append(reseated.battles, b)
+	}
+	return reseated
+}
+
+// report prints win rates, VP, withdrawals and game lengths
+func report(out io.Writer, names [2]string, results []*gameResult) {
+	var played, battles, actions int
+	var wins, vp [2]int
+	withdrawals := make(map[withdrawal]int)
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		played++
+		wins[result.winner]++
+		actions += result.actions
+		battles += len(result.battles)
+		for _, battle := range result.battles {
+			vp[0] += battle.vp[0]
+			vp[1] += battle.vp[1]
+			if battle.withdrawal != nil {
+				withdrawals[*battle.withdrawal]++
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "%d games played, %d abandoned\n\n", played, len(results)-played)
+	if played == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "agent\twins\twin rate\tVP per battle")
+	for i, name := range names {
+		fmt.Fprintf(w, "%s\t%d\t%.1f%%\t%.2f\n", name, wins[i], percent(wins[i], played), float64(vp[i])/float64(battles))
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\nwithdrawals")
+	keys := make([]withdrawal, 0, len(withdrawals))
+	for key := range withdrawals {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].firstPlayer != keys[j].firstPlayer {
+			return keys[i].firstPlayer
+		}
+		return keys[i].vp < keys[j].vp
+	})
+	fmt.Fprintln(w, "withdrawing player\tVP conceded\tcount\tof battles")
+	total := 0
+	for _, key := range keys {
+		role := "second"
+		if key.firstPlayer {
+			role = "first"
+		}
+		total += withdrawals[key]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\n", role, key.vp, withdrawals[key], percent(withdrawals[key], battles))
+	}
+	fmt.Fprintf(w, "any\t\t%d\t%.1f%%\n", total, percent(total, battles))
+	w.Flush()
+
+	fmt.Fprintf(out, "\naverage game: %.2f battles, %.1f actions\n",
+		float64(battles)/float64(played), float64(actions)/float64(played))
+}
+
+func percent(n, of int) float64 {
+	return 100 * float64(n) / float64(of)
+}
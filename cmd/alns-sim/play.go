@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/dfturn/alns/bot"
+	"github.com/dfturn/alns/models"
+	"github.com/dfturn/alns/service"
+)
+
+// withdrawal identifies a calculateWithdrawalVP bracket: whether the
+// withdrawing player went first, and the VP the opponent was awarded
+type withdrawal struct {
+	firstPlayer bool
+	vp          int
+}
+
+// battleResult is the outcome of one battle, indexed by seat
+type battleResult struct {
+	vp         [2]int
+	withdrawal *withdrawal
+}
+
+// gameResult is the outcome of one game, indexed by seat
+type gameResult struct {
+	winner  int
+	battles []battleResult
+	actions int
+}
+
+// playGame plays one game between two agents on a fresh in-process
+// service. Everything random in the game is drawn from seed.
+func playGame(seed int64, agents [2]bot.Agent, maxActions int) (*gameResult, error) {
+	svc := service.NewSeededGameService(service.NewMemoryStore(), seed)
+	room, err := svc.CreateRoom(agents[0].Name(), models.RoomOptions{})
+	if err != nil {
+		return nil, err
+	}
+	room, game, err := svc.JoinRoom(room.ID, agents[1].Name())
+	if err != nil {
+		return nil, err
+	}
+
+	seats := map[string]int{room.Player1.ID: 0, room.Player2.ID: 1}
+	rng := rand.New(rand.NewSource(seed))
+	result := &gameResult{}
+	startScores := [2]int{}
+
+	for {
+		if game.Phase == models.PhasePlaying {
+			if result.actions >= maxActions {
+				return nil, fmt.Errorf("no winner after %d actions", maxActions)
+			}
+			result.actions++
+
+			playerID := service.ActingPlayerID(game)
+			action, err := agents[seats[playerID]].ChooseAction(service.NewGameView(game, playerID))
+			if err == nil {
+				game, err = submit(svc, game, action)
+			}
+			if err != nil {
+				// Keep the game going with any legal move
+				action, err = fallbackAction(game, playerID, rng)
+				if err != nil {
+					return nil, err
+				}
+				if game, err = submit(svc, game, action); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		// The battle is over: both players accept the server's totals
+		for _, player := range []models.Player{game.Player1, game.Player2} {
+			if game.Phase != models.PhaseScoring || game.BattleWinnerID != "" {
+				break
+			}
+			if game, err = svc.UpdateTheaterScores(game.ID, player.ID, game.Version, serverTotals(game)); err != nil {
+				return nil, err
+			}
+		}
+
+		battle := battleResult{vp: [2]int{
+			game.Player1.Score - startScores[0],
+			game.Player2.Score - startScores[1],
+		}}
+		if game.WithdrewPlayerID != "" {
+			seat := seats[game.WithdrewPlayerID]
+			battle.withdrawal = &withdrawal{
+				firstPlayer: game.WithdrewPlayerID == game.FirstPlayerID,
+				vp:          battle.vp[1-seat],
+			}
+		}
+		result.battles = append(result.battles, battle)
+
+		if game.Phase == models.PhaseGameOver {
+			result.winner = 0
+			if game.Player2.Score > game.Player1.Score {
+				result.winner = 1
+			}
+			return result, nil
+		}
+
+		startScores = [2]int{game.Player1.Score, game.Player2.Score}
+		if game, err = svc.StartNextBattle(game.ID, game.Player1.ID, game.Version); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// submit performs an action and returns the game it led to
+func submit(svc *service.GameService, game *models.GameState, action models.Action) (*models.GameState, error) {
+	if err := bot.Submit(svc, game.ID, game.Version, action); err != nil {
+		return game, err
+	}
+	return svc.GetGame(game.ID)
+}
+
+// fallbackAction picks a random legal move other than withdrawing
+func fallbackAction(game *models.GameState, playerID string, rng *rand.Rand) (models.Action, error) {
+	var actions []models.Action
+	for _, action := range service.LegalActions(game, playerID) {
+		if action.Type != models.ActionWithdraw {
+			actions = append(actions, action)
+		}
+	}
+	if len(actions) == 0 {
+		return models.Action{}, fmt.Errorf("player %s has no legal action", playerID)
+	}
+	return actions[rng.Intn(len(actions))], nil
+}
+
+// serverTotals returns the theater totals the server computed
+func serverTotals(game *models.GameState) map[models.TheaterType]models.TheaterScore {
+	totals := make(map[models.TheaterType]models.TheaterScore, len(game.TheaterScores))
+	for theater, score := range game.TheaterScores {
+		totals[theater] = *score
+	}
+	return totals
+}
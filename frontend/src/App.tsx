@@ -1,11 +1,14 @@
 import { useState } from "react";
 import RoomLobby from "./components/RoomLobby";
 import GameBoard from "./components/GameBoard";
+import SpectatorBoard from "./components/SpectatorBoard";
 
 function App() {
   const [gameId, setGameId] = useState<string | null>(null);
   const [playerId, setPlayerId] = useState<string | null>(null);
   const [roomId, setRoomId] = useState<string | null>(null);
+  // Set while watching a game rather than playing it
+  const [spectatorDelay, setSpectatorDelay] = useState<number | null>(null);
 
   const handleGameStart = (
     newGameId: string,
@@ -17,11 +20,49 @@ function App() {
     setRoomId(newRoomId);
   };
 
+  const handleSpectate = (
+    newGameId: string,
+    spectatorId: string,
+    newRoomId: string,
+    delay: number
+  ) => {
+    handleGameStart(newGameId, spectatorId, newRoomId);
+    setSpectatorDelay(delay);
+  };
+
+  const handleLeave = () => {
+    setGameId(null);
+    setPlayerId(null);
+    setRoomId(null);
+    setSpectatorDelay(null);
+  };
+
+  if (gameId && playerId && roomId && spectatorDelay !== null) {
+    return (
+      <SpectatorBoard
+        gameId={gameId}
+        spectatorId={playerId}
+        roomId={roomId}
+        spectatorDelay={spectatorDelay}
+        onLeave={handleLeave}
+      />
+    );
+  }
+
   if (gameId && playerId && roomId) {
-    return <GameBoard gameId={gameId} playerId={playerId} roomId={roomId} />;
+    return (
+      <GameBoard
+        gameId={gameId}
+        playerId={playerId}
+        roomId={roomId}
+        onLeave={handleLeave}
+      />
+    );
   }
 
-  return <RoomLobby onGameStart={handleGameStart} />;
+  return (
+    <RoomLobby onGameStart={handleGameStart} onSpectate={handleSpectate} />
+  );
 }
 
 export default App;
@@ -1,33 +1,115 @@
 import type {
+  AbilityChoice,
   CreateRoomResponse,
+  ErrorResponse,
   JoinRoomResponse,
+  LobbyRoom,
+  MatchResponse,
+  ReclaimSeatResponse,
+  SavedSeat,
+  SpectateResponse,
   GameState,
   Room,
+  RoomOptions,
+  TheaterScore,
   TheaterType,
 } from "./types";
 
+const SAVED_SEAT_KEY = "alns.seat";
+
+// ApiError is thrown for error responses. Code is the server's stable
+// error code, e.g. "not_your_turn" or "session_replaced".
+export class ApiError extends Error {
+  status: number;
+  code: string;
+
+  constructor(message: string, status: number, code: string) {
+    super(message);
+    this.name = "ApiError";
+    this.status = status;
+    this.code = code;
+  }
+}
+
+// Builds the ApiError for a failed response, keeping the server's message
+// when it sent one
+async function apiError(
+  response: Response,
+  fallback: string
+): Promise<ApiError> {
+  try {
+    const body: ErrorResponse = await response.json();
+    return new ApiError(body.message || fallback, response.status, body.code);
+  } catch {
+    return new ApiError(fallback, response.status, "unknown");
+  }
+}
+
 class ApiClient {
   private baseUrl: string;
+  private sessionToken: string | null = null;
 
   constructor(baseUrl?: string) {
     this.baseUrl =
       baseUrl || import.meta.env.DEV ? "http://localhost:8080" : "";
   }
 
-  async createRoom(playerName: string): Promise<CreateRoomResponse> {
+  // The session token issued on create/join identifies the player on
+  // every game request.
+  setSessionToken(token: string) {
+    this.sessionToken = token;
+  }
+
+  // Remembers the recovery code of the player's seat across page loads
+  private saveSeat(roomId: string, recoveryCode: string) {
+    const seat: SavedSeat = { roomId, recoveryCode };
+    localStorage.setItem(SAVED_SEAT_KEY, JSON.stringify(seat));
+  }
+
+  savedSeat(): SavedSeat | null {
+    const saved = localStorage.getItem(SAVED_SEAT_KEY);
+    return saved ? JSON.parse(saved) : null;
+  }
+
+  // Forgets the saved seat once its room has been left
+  private forgetSeat(roomId: string) {
+    if (this.savedSeat()?.roomId === roomId) {
+      localStorage.removeItem(SAVED_SEAT_KEY);
+    }
+  }
+
+  private authHeaders(): Record<string, string> {
+    return this.sessionToken
+      ? { Authorization: `Bearer ${this.sessionToken}` }
+      : {};
+  }
+
+  // Game actions name the version they were made against, so the server
+  // turns them away with version_conflict once the game has moved on
+  private actionHeaders(version: number): Record<string, string> {
+    return { ...this.authHeaders(), "If-Match": `"${version}"` };
+  }
+
+  async createRoom(
+    playerName: string,
+    options: RoomOptions = {}
+  ): Promise<CreateRoomResponse> {
     const response = await fetch(`${this.baseUrl}/api/rooms`, {
       method: "POST",
       headers: {
         "Content-Type": "application/json",
       },
-      body: JSON.stringify({ playerName }),
+      body: JSON.stringify({ playerName, options }),
     });
 
     if (!response.ok) {
-      throw new Error("Failed to create room");
+      throw await apiError(response, "Failed to create room");
     }
 
-    return response.json();
+    const data: CreateRoomResponse = await response.json();
+    this.setSessionToken(data.token);
+    this.saveSeat(data.room.id, data.recoveryCode);
+    return data;
   }
 
   async joinRoom(
@@ -43,35 +125,203 @@ class ApiClient {
     });
 
     if (!response.ok) {
-      throw new Error("Failed to join room");
+      throw await apiError(response, "Failed to join room");
+    }
+
+    const data: JoinRoomResponse = await response.json();
+    this.setSessionToken(data.token);
+    this.saveSeat(data.room.id, data.recoveryCode);
+    return data;
+  }
+
+  // Starts watching a room as a spectator
+  async spectate(roomId: string): Promise<SpectateResponse> {
+    const response = await fetch(
+      `${this.baseUrl}/api/rooms/${roomId}/spectate`,
+      {
+        method: "POST",
+      }
+    );
+
+    if (!response.ok) {
+      throw await apiError(response, "Failed to spectate room");
+    }
+
+    const data: SpectateResponse = await response.json();
+    this.setSessionToken(data.token);
+    return data;
+  }
+
+  // Takes a seat back with its recovery code. Any other session in the
+  // seat is signed out.
+  async reclaimSeat(
+    roomId: string,
+    recoveryCode: string
+  ): Promise<ReclaimSeatResponse> {
+    const response = await fetch(
+      `${this.baseUrl}/api/rooms/${roomId}/reclaim`,
+      {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+        },
+        body: JSON.stringify({ recoveryCode }),
+      }
+    );
+
+    if (!response.ok) {
+      throw await apiError(response, "Failed to reclaim seat");
+    }
+
+    const data: ReclaimSeatResponse = await response.json();
+    this.setSessionToken(data.token);
+    this.saveSeat(data.room.id, data.recoveryCode);
+    return data;
+  }
+
+  // Lists the public rooms waiting for a second player
+  async listLobby(): Promise<LobbyRoom[]> {
+    const response = await fetch(`${this.baseUrl}/api/lobby`);
+
+    if (!response.ok) {
+      throw await apiError(response, "Failed to list rooms");
     }
 
     return response.json();
   }
 
+  // Joins the quick-match queue. The ticket may come back matched right
+  // away; otherwise poll it with getMatch.
+  async quickMatch(
+    playerName: string,
+    options: RoomOptions = {}
+  ): Promise<MatchResponse> {
+    const response = await fetch(`${this.baseUrl}/api/matchmaking`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+      },
+      body: JSON.stringify({ playerName, options }),
+    });
+
+    if (!response.ok) {
+      throw await apiError(response, "Failed to start quick match");
+    }
+
+    return this.takeMatch(await response.json());
+  }
+
+  async getMatch(ticketId: string): Promise<MatchResponse> {
+    const response = await fetch(
+      `${this.baseUrl}/api/matchmaking/${ticketId}`
+    );
+
+    if (!response.ok) {
+      throw await apiError(response, "Failed to get match");
+    }
+
+    return this.takeMatch(await response.json());
+  }
+
+  async cancelMatch(ticketId: string): Promise<void> {
+    const response = await fetch(
+      `${this.baseUrl}/api/matchmaking/${ticketId}`,
+      {
+        method: "DELETE",
+      }
+    );
+
+    if (!response.ok) {
+      throw await apiError(response, "Failed to cancel match");
+    }
+  }
+
+  // Takes the seat of a matched ticket
+  private takeMatch(data: MatchResponse): MatchResponse {
+    if (data.token && data.room && data.recoveryCode) {
+      this.setSessionToken(data.token);
+      this.saveSeat(data.room.id, data.recoveryCode);
+    }
+    return data;
+  }
+
   async getRoom(roomId: string): Promise<Room> {
     const response = await fetch(`${this.baseUrl}/api/rooms/${roomId}`);
 
     if (!response.ok) {
-      throw new Error("Failed to get room");
+      throw await apiError(response, "Failed to get room");
+    }
+
+    return response.json();
+  }
+
+  async leaveRoom(roomId: string): Promise<Room> {
+    const response = await fetch(`${this.baseUrl}/api/rooms/${roomId}/leave`, {
+      method: "POST",
+      headers: this.authHeaders(),
+    });
+
+    if (!response.ok) {
+      throw await apiError(response, "Failed to leave room");
+    }
+
+    this.forgetSeat(roomId);
+    return response.json();
+  }
+
+  async closeRoom(roomId: string): Promise<Room> {
+    const response = await fetch(`${this.baseUrl}/api/rooms/${roomId}/close`, {
+      method: "POST",
+      headers: this.authHeaders(),
+    });
+
+    if (!response.ok) {
+      throw await apiError(response, "Failed to close room");
     }
 
+    this.forgetSeat(roomId);
     return response.json();
   }
 
   async getGame(gameId: string): Promise<GameState> {
-    const response = await fetch(`${this.baseUrl}/api/games/${gameId}`);
+    const response = await fetch(`${this.baseUrl}/api/games/${gameId}`, {
+      headers: this.authHeaders(),
+    });
 
     if (!response.ok) {
-      throw new Error("Failed to get game");
+      throw await apiError(response, "Failed to get game");
     }
 
     return response.json();
   }
 
+  // Streams the player's view of the game. EventSource reconnects on its
+  // own and resumes from the last version it saw via Last-Event-ID.
+  // EventSource cannot set headers, so the token goes in the query string.
+  // The server sends "closed" once the game has been deleted.
+  subscribeToGame(
+    gameId: string,
+    onUpdate: (game: GameState) => void,
+    onClosed?: () => void
+  ): EventSource {
+    const source = new EventSource(
+      `${this.baseUrl}/api/games/${gameId}/events?token=${encodeURIComponent(
+        this.sessionToken ?? ""
+      )}`
+    );
+    source.addEventListener("game", (event) => {
+      onUpdate(JSON.parse((event as MessageEvent).data));
+    });
+    source.addEventListener("closed", () => {
+      source.close();
+      onClosed?.();
+    });
+    return source;
+  }
+
   async playCard(
     gameId: string,
-    playerId: string,
+    version: number,
     cardId: number,
     theater: TheaterType,
     faceUp: boolean
@@ -82,51 +332,46 @@ class ApiClient {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
+          ...this.actionHeaders(version),
         },
-        body: JSON.stringify({ playerId, cardId, theater, faceUp }),
+        body: JSON.stringify({ cardId, theater, faceUp }),
       }
     );
 
     if (!response.ok) {
-      throw new Error("Failed to play card");
+      throw await apiError(response, "Failed to play card");
     }
 
     return response.json();
   }
 
-  async endTurn(gameId: string, playerId: string): Promise<GameState> {
+  async endTurn(gameId: string, version: number): Promise<GameState> {
     const response = await fetch(
       `${this.baseUrl}/api/games/${gameId}/end-turn`,
       {
         method: "POST",
-        headers: {
-          "Content-Type": "application/json",
-        },
-        body: JSON.stringify({ playerId }),
+        headers: this.actionHeaders(version),
       }
     );
 
     if (!response.ok) {
-      throw new Error("Failed to end turn");
+      throw await apiError(response, "Failed to end turn");
     }
 
     return response.json();
   }
 
-  async drawCard(gameId: string, playerId: string): Promise<GameState> {
+  async drawCard(gameId: string, version: number): Promise<GameState> {
     const response = await fetch(
       `${this.baseUrl}/api/games/${gameId}/draw-card`,
       {
         method: "POST",
-        headers: {
-          "Content-Type": "application/json",
-        },
-        body: JSON.stringify({ playerId }),
+        headers: this.actionHeaders(version),
       }
     );
 
     if (!response.ok) {
-      throw new Error("Failed to draw card");
+      throw await apiError(response, "Failed to draw card");
     }
 
     return response.json();
@@ -134,7 +379,7 @@ class ApiClient {
 
   async manipulateCard(
     gameId: string,
-    playerId: string,
+    version: number,
     theater: TheaterType,
     cardId: number,
     action: "flip" | "destroy" | "return"
@@ -145,13 +390,14 @@ class ApiClient {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
+          ...this.actionHeaders(version),
         },
-        body: JSON.stringify({ playerId, theater, cardId, action }),
+        body: JSON.stringify({ theater, cardId, action }),
       }
     );
 
     if (!response.ok) {
-      throw new Error("Failed to manipulate card");
+      throw await apiError(response, "Failed to manipulate card");
     }
 
     return response.json();
@@ -159,7 +405,7 @@ class ApiClient {
 
   async destroyCard(
     gameId: string,
-    playerId: string,
+    version: number,
     cardId: number
   ): Promise<GameState> {
     const response = await fetch(
@@ -168,32 +414,54 @@ class ApiClient {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
+          ...this.actionHeaders(version),
         },
-        body: JSON.stringify({ playerId, cardId }),
+        body: JSON.stringify({ cardId }),
       }
     );
 
     if (!response.ok) {
-      throw new Error("Failed to destroy card");
+      throw await apiError(response, "Failed to destroy card");
     }
 
     return response.json();
   }
 
-  async withdraw(gameId: string, playerId: string): Promise<GameState> {
+  async resolveAbility(
+    gameId: string,
+    version: number,
+    choice: AbilityChoice
+  ): Promise<GameState> {
     const response = await fetch(
-      `${this.baseUrl}/api/games/${gameId}/withdraw`,
+      `${this.baseUrl}/api/games/${gameId}/resolve-ability`,
       {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
+          ...this.actionHeaders(version),
         },
-        body: JSON.stringify({ playerId }),
+        body: JSON.stringify({ choice }),
+      }
+    );
+
+    if (!response.ok) {
+      throw await apiError(response, "Failed to resolve ability");
+    }
+
+    return response.json();
+  }
+
+  async withdraw(gameId: string, version: number): Promise<GameState> {
+    const response = await fetch(
+      `${this.baseUrl}/api/games/${gameId}/withdraw`,
+      {
+        method: "POST",
+        headers: this.actionHeaders(version),
       }
     );
 
     if (!response.ok) {
-      throw new Error("Failed to withdraw");
+      throw await apiError(response, "Failed to withdraw");
     }
 
     return response.json();
@@ -201,8 +469,8 @@ class ApiClient {
 
   async updateScores(
     gameId: string,
-    playerId: string,
-    scores: Record<TheaterType, number>
+    version: number,
+    scores: Record<TheaterType, TheaterScore>
   ): Promise<GameState> {
     const response = await fetch(
       `${this.baseUrl}/api/games/${gameId}/update-scores`,
@@ -210,43 +478,62 @@ class ApiClient {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
+          ...this.actionHeaders(version),
         },
-        body: JSON.stringify({ playerId, scores }),
+        body: JSON.stringify({ scores }),
+      }
+    );
+
+    if (!response.ok) {
+      throw await apiError(response, "Failed to update scores");
+    }
+
+    return response.json();
+  }
+
+  async acceptScores(gameId: string, version: number): Promise<GameState> {
+    const response = await fetch(
+      `${this.baseUrl}/api/games/${gameId}/accept-scores`,
+      {
+        method: "POST",
+        headers: this.actionHeaders(version),
       }
     );
 
     if (!response.ok) {
-      throw new Error("Failed to update scores");
+      throw await apiError(response, "Failed to accept scores");
     }
 
     return response.json();
   }
 
-  async startNextBattle(gameId: string): Promise<GameState> {
+  async startNextBattle(gameId: string, version: number): Promise<GameState> {
     const response = await fetch(
       `${this.baseUrl}/api/games/${gameId}/next-battle`,
       {
         method: "POST",
+        headers: this.actionHeaders(version),
       }
     );
 
     if (!response.ok) {
-      throw new Error("Failed to start next battle");
+      throw await apiError(response, "Failed to start next battle");
     }
 
     return response.json();
   }
 
-  async startNextGame(gameId: string): Promise<GameState> {
+  async startNextGame(gameId: string, version: number): Promise<GameState> {
     const response = await fetch(
       `${this.baseUrl}/api/games/${gameId}/next-game`,
       {
         method: "POST",
+        headers: this.actionHeaders(version),
       }
     );
 
     if (!response.ok) {
-      throw new Error("Failed to start next game");
+      throw await apiError(response, "Failed to start next game");
     }
 
     return response.json();
@@ -44,8 +44,7 @@ export default function Card({
   onDoubleTap,
   doubleTapThreshold = 300,
 }: CardProps) {
-  const cardName = card?.name || "card-back";
-  const backgroundPosition = getCardBackgroundPosition(cardName, faceUp);
+  const backgroundPosition = getCardBackgroundPosition(card?.id ?? null, faceUp);
   const backgroundSize = getCardBackgroundSize();
   const longPressTimer = useRef<number | null>(null);
   const longPressTriggered = useRef(false);
@@ -14,15 +14,23 @@ import {
   PlayerHand,
   TouchDragOverlay,
 } from "./game";
+import type { ScoreInput } from "./game";
 
 interface GameBoardProps {
   gameId: string;
   playerId: string;
   roomId: string;
+  onLeave: () => void;
 }
 
 const DEFAULT_THEATER_ORDER: TheaterType[] = ["air", "land", "sea"];
 
+const EMPTY_SCORE_INPUTS: Record<TheaterType, ScoreInput> = {
+  air: { self: "", opponent: "" },
+  land: { self: "", opponent: "" },
+  sea: { self: "", opponent: "" },
+};
+
 const formatTheaterName = (theater: TheaterType) =>
   theater.charAt(0).toUpperCase() + theater.slice(1);
 
@@ -35,7 +43,12 @@ const rotateTheaterOrder = (order: TheaterType[]) => {
   return rotated;
 };
 
-export default function GameBoard({ gameId, playerId }: GameBoardProps) {
+export default function GameBoard({
+  gameId,
+  playerId,
+  roomId,
+  onLeave,
+}: GameBoardProps) {
   const {
     gameState,
     isLoading,
@@ -47,9 +60,18 @@ export default function GameBoard({ gameId, playerId }: GameBoardProps) {
     drawCard,
     withdraw,
     submitScores,
+    acceptScores,
     startNextBattle,
     startNextGame,
-  } = useGameState({ gameId, playerId });
+    leaveRoom,
+  } = useGameState({ gameId, playerId, roomId });
+
+  const handleLeave = async () => {
+    // Once someone has left, the room is closed and there is nothing to leave
+    if (gameState?.leftPlayerId || (await leaveRoom())) {
+      onLeave();
+    }
+  };
 
   const {
     preview,
@@ -78,63 +100,60 @@ export default function GameBoard({ gameId, playerId }: GameBoardProps) {
     isMyTurn: gameState?.currentPlayerId === playerId,
     isPlaying: gameState?.phase === "playing",
     isLoading,
+    isSandbox: gameState?.mode === "sandbox",
     onPlayCard: playCard,
     onDestroyCard: destroyCard,
     onManipulateCard: manipulateCard,
     getCardFaceUp,
   });
 
-  const [scoreInputs, setScoreInputs] = useState<Record<TheaterType, string>>({
-    air: "",
-    land: "",
-    sea: "",
-  });
+  const [scoreInputs, setScoreInputs] =
+    useState<Record<TheaterType, ScoreInput>>(EMPTY_SCORE_INPUTS);
 
   const autoAdvanceBattleRef = useRef<number | null>(null);
+  const prefilledBattleRef = useRef<number | null>(null);
 
   // Score input handling
   const handleScoreInputChange = useCallback(
-    (theater: TheaterType, value: string) => {
+    (theater: TheaterType, side: keyof ScoreInput, value: string) => {
       const sanitized = value.replace(/[^0-9]/g, "");
-      setScoreInputs((prev) => ({ ...prev, [theater]: sanitized }));
+      setScoreInputs((prev) => ({
+        ...prev,
+        [theater]: { ...prev[theater], [side]: sanitized },
+      }));
     },
     []
   );
 
-  // Reset score inputs when phase changes or when pre-filled from server
+  // Reset score inputs when phase changes; pre-fill them once per battle
+  // from the player's own submission or the server's totals
   useEffect(() => {
     if (!gameState) return;
 
     if (gameState.phase !== "scoring" || gameState.withdrewPlayerId) {
-      setScoreInputs({ air: "", land: "", sea: "" });
+      prefilledBattleRef.current = null;
+      setScoreInputs(EMPTY_SCORE_INPUTS);
       return;
     }
 
+    const source =
+      gameState.scoreSubmissions?.[playerId] ?? gameState.theaterScores;
+    if (!source || prefilledBattleRef.current === gameState.battleNumber) {
+      return;
+    }
+    prefilledBattleRef.current = gameState.battleNumber;
+
     const isPlayer1 = gameState.player1.id === playerId;
-    const theaterScores = (gameState.theaterScores ?? {}) as Partial<
-      Record<TheaterType, TheaterScore>
-    >;
-
-    setScoreInputs((prev) => {
-      let changed = false;
-      const next: Record<TheaterType, string> = { ...prev };
-      (DEFAULT_THEATER_ORDER as TheaterType[]).forEach((theater) => {
-        const entry = theaterScores[theater];
-        const recorded = entry
-          ? isPlayer1
-            ? entry.player1Total
-            : entry.player2Total
-          : 0;
-        if (recorded > 0) {
-          const value = String(recorded);
-          if (next[theater] !== value) {
-            next[theater] = value;
-            changed = true;
-          }
-        }
-      });
-      return changed ? next : prev;
+    const next = { ...EMPTY_SCORE_INPUTS };
+    (DEFAULT_THEATER_ORDER as TheaterType[]).forEach((theater) => {
+      const entry = source[theater];
+      if (!entry) return;
+      next[theater] = {
+        self: String(isPlayer1 ? entry.player1Total : entry.player2Total),
+        opponent: String(isPlayer1 ? entry.player2Total : entry.player1Total),
+      };
     });
+    setScoreInputs(next);
   }, [gameState, playerId]);
 
   // Auto-advance battle when someone withdrew
@@ -210,24 +229,22 @@ export default function GameBoard({ gameId, playerId }: GameBoardProps) {
     Record<TheaterType, TheaterScore>
   >;
 
-  const getRecordedScore = (theater: TheaterType, forSelf: boolean) => {
-    const record = theaterScoresRecord[theater];
-    if (!record) return 0;
-    if (forSelf) {
-      return isPlayer1 ? record.player1Total : record.player2Total;
-    }
-    return isPlayer1 ? record.player2Total : record.player1Total;
-  };
-
-  const playerSubmitted =
-    isScoringPhase &&
-    theaterOrder.every((theater) => getRecordedScore(theater, true) > 0);
+  const ownSubmission = gameState.scoreSubmissions?.[playerId];
+  const playerSubmitted = isScoringPhase && !!ownSubmission;
   const opponentSubmitted =
+    isScoringPhase && !!gameState.scoreSubmissions?.[opponent.id];
+  const battleDecided = isScoringPhase && !!gameState.battleWinnerId;
+  const disputedTheaters = gameState.disputedTheaters ?? [];
+  const scoresEditable =
     isScoringPhase &&
-    theaterOrder.every((theater) => getRecordedScore(theater, false) > 0);
-  const bothSubmitted = playerSubmitted && opponentSubmitted;
+    !battleDecided &&
+    (!playerSubmitted || disputedTheaters.length > 0);
+  const displayedScores =
+    playerSubmitted && !battleDecided ? ownSubmission : theaterScoresRecord;
   const allScoresEntered = theaterOrder.every(
-    (theater) => scoreInputs[theater]?.trim().length
+    (theater) =>
+      scoreInputs[theater]?.self.trim().length &&
+      scoreInputs[theater]?.opponent.trim().length
   );
 
   const preferredTheater =
@@ -260,13 +277,16 @@ export default function GameBoard({ gameId, playerId }: GameBoardProps) {
 
   // Event handlers
   const handleSubmitScores = async () => {
-    if (!isScoringPhase || playerSubmitted) return;
-
-    const payload: Record<TheaterType, number> = {
-      air: Number(scoreInputs.air || 0),
-      land: Number(scoreInputs.land || 0),
-      sea: Number(scoreInputs.sea || 0),
-    };
+    if (!scoresEditable) return;
+
+    const payload = {} as Record<TheaterType, TheaterScore>;
+    theaterOrder.forEach((theater) => {
+      const self = Number(scoreInputs[theater].self || 0);
+      const other = Number(scoreInputs[theater].opponent || 0);
+      payload[theater] = isPlayer1
+        ? { player1Total: self, player2Total: other }
+        : { player1Total: other, player2Total: self };
+    });
 
     await submitScores(payload);
   };
@@ -280,16 +300,19 @@ export default function GameBoard({ gameId, playerId }: GameBoardProps) {
   const canDragHandCards =
     isMyTurn && gameState.phase === "playing" && !isLoading;
   const shouldShowActions = gameState.phase === "playing" && isMyTurn;
+  const isSandbox = gameState.mode === "sandbox";
 
   const actionButtons = shouldShowActions ? (
     <>
-      <button
-        onClick={endTurn}
-        disabled={isLoading}
-        className="btn btn-primary btn-lg fw-bold"
-      >
-        End Turn
-      </button>
+      {isSandbox && (
+        <button
+          onClick={endTurn}
+          disabled={isLoading}
+          className="btn btn-primary btn-lg fw-bold"
+        >
+          End Turn
+        </button>
+      )}
       <button
         onClick={handleWithdraw}
         disabled={isLoading}
@@ -325,8 +348,15 @@ export default function GameBoard({ gameId, playerId }: GameBoardProps) {
         <GameOverModal
           currentPlayer={currentPlayer}
           opponent={opponent}
+          winnerId={gameState.winnerId}
+          forfeitedPlayerId={gameState.forfeitedPlayerId}
+          leftPlayerId={gameState.leftPlayerId}
+          battleResults={gameState.battleResults ?? []}
+          series={gameState.series}
+          isPlayer1={isPlayer1}
           isLoading={isLoading}
           onStartNextGame={startNextGame}
+          onLeave={handleLeave}
         />
       )}
 
@@ -336,23 +366,26 @@ export default function GameBoard({ gameId, playerId }: GameBoardProps) {
         opponent={opponent}
         isMyTurn={isMyTurn}
         theaterLabel={theaterLabel}
+        isLoading={isLoading}
+        onLeave={handleLeave}
       />
 
       <div className="flex-grow-1 container-fluid px-4 pb-4 board-scroll">
         <div className="board-layout gap-4">
           <div className="board-main d-flex flex-column align-items-stretch gap-4">
-            <OpponentHand cardCount={opponent.hand.length} />
+            <OpponentHand cardCount={opponent.handCount} />
 
             <TheaterGrid
               theaterOrder={theaterOrder}
               preferredTheater={preferredTheater}
               isScoringPhase={isScoringPhase}
-              playerSubmitted={playerSubmitted}
+              scoresEditable={scoresEditable}
+              disputedTheaters={disputedTheaters}
               isLoading={isLoading}
               isMyTurn={isMyTurn}
               playerId={playerId}
               opponentName={opponent.name}
-              theaterScores={theaterScoresRecord}
+              theaterScores={displayedScores ?? null}
               isPlayer1={isPlayer1}
               scoreInputs={scoreInputs}
               theaters={gameState.theaters}
@@ -365,6 +398,7 @@ export default function GameBoard({ gameId, playerId }: GameBoardProps) {
               onTheaterCardDragStart={handleTheaterCardDragStart}
               onTheaterCardDragEnd={handleDragEnd}
               onTheaterCardClick={(theater, playedCard) => {
+                if (!isSandbox) return;
                 const topCard = getTopCardInStack(theater, playedCard.playerId);
                 if (!topCard) return;
                 manipulateCard(theater, topCard.card.id, "flip");
@@ -387,19 +421,24 @@ export default function GameBoard({ gameId, playerId }: GameBoardProps) {
               <ScoringActionsPanel
                 playerSubmitted={playerSubmitted}
                 opponentSubmitted={opponentSubmitted}
+                scoresEditable={scoresEditable}
+                battleDecided={battleDecided}
+                disputedTheaters={disputedTheaters}
                 allScoresEntered={allScoresEntered}
                 isLoading={isLoading}
                 showStartNextBattle={gameState.phase === "scoring"}
                 nextBattleLabel={nextBattleLabel}
                 onSubmitScores={handleSubmitScores}
+                onAcceptScores={acceptScores}
                 onStartNextBattle={startNextBattle}
               />
             )}
 
-            {isScoringPhase && bothSubmitted && (
+            {battleDecided && (
               <ScoringResultsPanel
                 theaterOrder={theaterOrder}
                 theaterScores={theaterScoresRecord}
+                battleWinnerId={gameState.battleWinnerId}
                 player1={gameState.player1}
                 player2={gameState.player2}
                 playerId={playerId}
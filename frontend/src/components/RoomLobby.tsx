@@ -1,17 +1,86 @@
-import { useState } from "react";
+import { useEffect, useRef, useState } from "react";
 import { apiClient } from "../api";
-import type { Room } from "../types";
+import type { ExpiryAction, LobbyRoom, Room, TimeControl } from "../types";
+
+const TIME_CONTROLS: { label: string; timeControl?: TimeControl }[] = [
+  { label: "No time limit" },
+  { label: "30 seconds per move", timeControl: { perMoveSeconds: 30 } },
+  { label: "1 minute per move", timeControl: { perMoveSeconds: 60 } },
+  {
+    label: "5 minutes + 5 seconds per move",
+    timeControl: { bankSeconds: 300, incrementSeconds: 5 },
+  },
+  {
+    label: "10 minutes + 10 seconds per move",
+    timeControl: { bankSeconds: 600, incrementSeconds: 10 },
+  },
+];
+
+// Short description of a room's time control for the open rooms list
+function describeTimeControl(timeControl?: TimeControl) {
+  if (!timeControl) {
+    return "No time limit";
+  }
+  if (timeControl.perMoveSeconds) {
+    return `${timeControl.perMoveSeconds}s per move`;
+  }
+  const minutes = Math.round((timeControl.bankSeconds ?? 0) / 60);
+  return `${minutes} min + ${timeControl.incrementSeconds ?? 0}s`;
+}
 
 interface RoomLobbyProps {
   onGameStart: (gameId: string, playerId: string, roomId: string) => void;
+  onSpectate: (
+    gameId: string,
+    spectatorId: string,
+    roomId: string,
+    spectatorDelay: number
+  ) => void;
 }
 
-export default function RoomLobby({ onGameStart }: RoomLobbyProps) {
+// Number of moves spectators lag behind, offered when creating a room
+const SPECTATOR_DELAYS = [0, 2, 4, 8];
+
+// Series lengths offered when creating a room
+const SERIES_LENGTHS = [1, 3, 5, 7];
+
+export default function RoomLobby({
+  onGameStart,
+  onSpectate,
+}: RoomLobbyProps) {
   const [playerName, setPlayerName] = useState("");
   const [roomId, setRoomId] = useState("");
   const [currentRoom, setCurrentRoom] = useState<Room | null>(null);
   const [error, setError] = useState("");
   const [isLoading, setIsLoading] = useState(false);
+  const [sandbox, setSandbox] = useState(false);
+  // 0 plays against a person, otherwise the bot difficulty level
+  const [botLevel, setBotLevel] = useState(0);
+  // Index into TIME_CONTROLS
+  const [timeControlIndex, setTimeControlIndex] = useState(0);
+  const [onExpiry, setOnExpiry] = useState<ExpiryAction>("withdraw");
+  const [bestOf, setBestOf] = useState(1);
+  const [savedSeat] = useState(() => apiClient.savedSeat());
+  const [recoveryCode, setRecoveryCode] = useState("");
+  const [allowSpectators, setAllowSpectators] = useState(false);
+  const [spectatorDelay, setSpectatorDelay] = useState(0);
+  // Room being watched until its game starts
+  const [watchingRoom, setWatchingRoom] = useState<Room | null>(null);
+  const watchPollRef = useRef<number | null>(null);
+  const [isPrivate, setIsPrivate] = useState(false);
+  const [lobby, setLobby] = useState<LobbyRoom[]>([]);
+  // Quick-match ticket while searching for an opponent
+  const [matchTicket, setMatchTicket] = useState<string | null>(null);
+  const matchPollRef = useRef<number | null>(null);
+
+  useEffect(() => {
+    apiClient.listLobby().then(setLobby).catch(console.error);
+  }, []);
+
+  const isSandbox = sandbox && !botLevel;
+  const timeControl = isSandbox
+    ? undefined
+    : TIME_CONTROLS[timeControlIndex].timeControl;
 
   const handleCreateRoom = async () => {
     if (!playerName.trim()) {
@@ -21,7 +90,20 @@ export default function RoomLobby({ onGameStart }: RoomLobbyProps) {
     setIsLoading(true);
     setError("");
     try {
-      const response = await apiClient.createRoom(playerName);
+      const response = await apiClient.createRoom(playerName, {
+        mode: isSandbox ? "sandbox" : "strict",
+        opponent: botLevel ? "bot" : "human",
+        level: botLevel || undefined,
+        timeControl: timeControl && { ...timeControl, onExpiry },
+        bestOf,
+        allowSpectators: allowSpectators || undefined,
+        spectatorDelay: (allowSpectators && spectatorDelay) || undefined,
+        private: isPrivate || undefined,
+      });
+      if (response.room.gameId) {
+        onGameStart(response.room.gameId, response.playerId, response.room.id);
+        return;
+      }
       setCurrentRoom(response.room);
       setRoomId(response.room.id);
       pollForPlayers(response.room.id, response.playerId);
@@ -33,19 +115,19 @@ export default function RoomLobby({ onGameStart }: RoomLobbyProps) {
     }
   };
 
-  const handleJoinRoom = async () => {
+  const handleJoinRoom = async (id: string) => {
     if (!playerName.trim()) {
       setError("Please enter your name");
       return;
     }
-    if (!roomId.trim()) {
+    if (!id.trim()) {
       setError("Please enter a room ID");
       return;
     }
     setIsLoading(true);
     setError("");
     try {
-      const response = await apiClient.joinRoom(roomId, playerName);
+      const response = await apiClient.joinRoom(id, playerName);
       setCurrentRoom(response.room);
       if (response.game) {
         onGameStart(response.game.id, response.playerId, response.room.id);
@@ -58,6 +140,154 @@ export default function RoomLobby({ onGameStart }: RoomLobbyProps) {
     }
   };
 
+  const handleRefreshLobby = async () => {
+    try {
+      setLobby(await apiClient.listLobby());
+    } catch (err) {
+      setError("Failed to list open rooms");
+      console.error(err);
+    }
+  };
+
+  const handleQuickMatch = async () => {
+    if (!playerName.trim()) {
+      setError("Please enter your name");
+      return;
+    }
+    setIsLoading(true);
+    setError("");
+    try {
+      const response = await apiClient.quickMatch(playerName, {
+        mode: isSandbox ? "sandbox" : "strict",
+        timeControl: timeControl && { ...timeControl, onExpiry },
+        bestOf,
+      });
+      if (response.game && response.playerId) {
+        onGameStart(response.game.id, response.playerId, response.game.roomId);
+        return;
+      }
+      setMatchTicket(response.ticketId);
+      matchPollRef.current = window.setInterval(async () => {
+        try {
+          const match = await apiClient.getMatch(response.ticketId);
+          if (match.game && match.playerId) {
+            stopMatchPoll();
+            onGameStart(match.game.id, match.playerId, match.game.roomId);
+          }
+        } catch (err) {
+          console.error("Error polling match:", err);
+          stopMatchPoll();
+          setMatchTicket(null);
+          setError("Quick match stopped. Please try again.");
+        }
+      }, 1000);
+    } catch (err) {
+      setError("Failed to start quick match");
+      console.error(err);
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  const stopMatchPoll = () => {
+    if (matchPollRef.current !== null) {
+      window.clearInterval(matchPollRef.current);
+      matchPollRef.current = null;
+    }
+  };
+
+  const handleCancelMatch = async () => {
+    if (!matchTicket) return;
+    stopMatchPoll();
+    setMatchTicket(null);
+    try {
+      await apiClient.cancelMatch(matchTicket);
+    } catch (err) {
+      console.error(err);
+    }
+  };
+
+  const handleReclaimSeat = async (room: string, code: string) => {
+    if (!room.trim() || !code.trim()) {
+      setError("Please enter the room ID and your recovery code");
+      return;
+    }
+    setIsLoading(true);
+    setError("");
+    try {
+      const response = await apiClient.reclaimSeat(room, code.trim());
+      if (response.game) {
+        onGameStart(response.game.id, response.playerId, response.room.id);
+        return;
+      }
+      setCurrentRoom(response.room);
+      setRoomId(response.room.id);
+      pollForPlayers(response.room.id, response.playerId);
+    } catch (err) {
+      setError("Failed to reclaim seat. Check the codes and try again.");
+      console.error(err);
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  const handleSpectate = async () => {
+    if (!roomId.trim()) {
+      setError("Please enter a room ID");
+      return;
+    }
+    setIsLoading(true);
+    setError("");
+    try {
+      const response = await apiClient.spectate(roomId);
+      const delay = response.room.options.spectatorDelay ?? 0;
+      if (response.game) {
+        onSpectate(response.game.id, response.spectatorId, roomId, delay);
+        return;
+      }
+      setWatchingRoom(response.room);
+      watchPollRef.current = window.setInterval(async () => {
+        try {
+          const room = await apiClient.getRoom(roomId);
+          if (room.gameId) {
+            stopWatchPoll();
+            onSpectate(room.gameId, response.spectatorId, roomId, delay);
+          } else if (room.status !== "waiting") {
+            stopWatchPoll();
+            setWatchingRoom(null);
+            setError("The room was closed");
+          }
+        } catch (err) {
+          console.error("Error polling room:", err);
+          stopWatchPoll();
+        }
+      }, 1000);
+    } catch (err) {
+      setError("Failed to watch room. It may not allow spectators.");
+      console.error(err);
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  const stopWatchPoll = () => {
+    if (watchPollRef.current !== null) {
+      window.clearInterval(watchPollRef.current);
+      watchPollRef.current = null;
+    }
+  };
+
+  const handleStopWatching = async () => {
+    if (!watchingRoom) return;
+    stopWatchPoll();
+    setWatchingRoom(null);
+    try {
+      await apiClient.leaveRoom(watchingRoom.id);
+    } catch (err) {
+      console.error(err);
+    }
+  };
+
   const pollForPlayers = async (roomId: string, playerId: string) => {
     const pollInterval = setInterval(async () => {
       try {
@@ -66,6 +296,8 @@ export default function RoomLobby({ onGameStart }: RoomLobbyProps) {
         if (room.status === "playing" && room.gameId) {
           clearInterval(pollInterval);
           onGameStart(room.gameId, playerId, roomId);
+        } else if (room.status !== "waiting") {
+          clearInterval(pollInterval);
         }
       } catch (err) {
         console.error("Error polling room:", err);
@@ -75,7 +307,86 @@ export default function RoomLobby({ onGameStart }: RoomLobbyProps) {
     setTimeout(() => clearInterval(pollInterval), 300000);
   };
 
+  const handleCloseRoom = async () => {
+    if (!currentRoom) return;
+    setIsLoading(true);
+    setError("");
+    try {
+      setCurrentRoom(await apiClient.closeRoom(currentRoom.id));
+      setRoomId("");
+    } catch (err) {
+      setError("Failed to close room");
+      console.error(err);
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  if (matchTicket) {
+    return (
+      <div className="lobby-bg d-flex align-items-center justify-content-center">
+        <div
+          className="card shadow-lg"
+          style={{ maxWidth: "450px", width: "100%" }}
+        >
+          <div className="card-body p-4 text-center">
+            <h2 className="card-title mb-4">Finding an Opponent...</h2>
+            <p className="text-muted">
+              {isSandbox ? "Sandbox" : "Strict"} rules ·{" "}
+              {describeTimeControl(timeControl)}
+            </p>
+            <div className="d-flex justify-content-center mb-3">
+              <div
+                className="spinner-border text-primary"
+                style={{ width: "3rem", height: "3rem" }}
+              />
+            </div>
+            <button
+              onClick={handleCancelMatch}
+              className="btn btn-outline-secondary w-100"
+            >
+              Cancel
+            </button>
+          </div>
+        </div>
+      </div>
+    );
+  }
+
+  if (watchingRoom) {
+    return (
+      <div className="lobby-bg d-flex align-items-center justify-content-center">
+        <div
+          className="card shadow-lg"
+          style={{ maxWidth: "450px", width: "100%" }}
+        >
+          <div className="card-body p-4 text-center">
+            <h2 className="card-title mb-4">Waiting for the Game...</h2>
+            <p className="text-muted">
+              You are watching room{" "}
+              <span className="font-monospace fw-bold">{watchingRoom.id}</span>.
+              The game will appear once a second player joins.
+            </p>
+            <div className="d-flex justify-content-center mb-3">
+              <div
+                className="spinner-border text-primary"
+                style={{ width: "3rem", height: "3rem" }}
+              />
+            </div>
+            <button
+              onClick={handleStopWatching}
+              className="btn btn-outline-secondary w-100"
+            >
+              Stop Watching
+            </button>
+          </div>
+        </div>
+      </div>
+    );
+  }
+
   if (currentRoom && currentRoom.status === "waiting") {
+    const ownSeat = apiClient.savedSeat();
     return (
       <div className="lobby-bg d-flex align-items-center justify-content-center">
         <div
@@ -113,6 +424,31 @@ export default function RoomLobby({ onGameStart }: RoomLobbyProps) {
             <p className="text-center text-muted">
               Game will start when another player joins
             </p>
+
+            {currentRoom.options.allowSpectators && (
+              <p className="text-center small text-muted">
+                {currentRoom.spectatorCount} watching
+              </p>
+            )}
+
+            {ownSeat?.roomId === currentRoom.id && (
+              <p className="text-center small text-muted">
+                Recovery code:{" "}
+                <span className="font-monospace fw-bold">
+                  {ownSeat.recoveryCode}
+                </span>
+              </p>
+            )}
+
+            {error && <div className="alert alert-danger">{error}</div>}
+
+            <button
+              onClick={handleCloseRoom}
+              disabled={isLoading}
+              className="btn btn-outline-secondary w-100"
+            >
+              {isLoading ? "Closing..." : "Close Room"}
+            </button>
           </div>
         </div>
       </div>
@@ -133,6 +469,18 @@ export default function RoomLobby({ onGameStart }: RoomLobbyProps) {
 
           {error && <div className="alert alert-danger">{error}</div>}
 
+          {savedSeat && (
+            <button
+              onClick={() =>
+                handleReclaimSeat(savedSeat.roomId, savedSeat.recoveryCode)
+              }
+              disabled={isLoading}
+              className="btn btn-outline-primary w-100 py-2 mb-4"
+            >
+              Rejoin Room {savedSeat.roomId}
+            </button>
+          )}
+
           <div className="mb-4">
             <label className="form-label fw-bold">Your Name</label>
             <input
@@ -145,20 +493,194 @@ export default function RoomLobby({ onGameStart }: RoomLobbyProps) {
             />
           </div>
 
+          <div className="mb-3">
+            <label className="form-label fw-bold">Opponent</label>
+            <select
+              value={botLevel}
+              onChange={(e) => setBotLevel(Number(e.target.value))}
+              className="form-select"
+              disabled={isLoading}
+            >
+              <option value={0}>Another player</option>
+              <option value={1}>Bot - easy</option>
+              <option value={2}>Bot - medium</option>
+              <option value={3}>Bot - hard</option>
+            </select>
+          </div>
+
+          <div className="form-check mb-3">
+            <input
+              type="checkbox"
+              id="sandbox-mode"
+              checked={isSandbox}
+              onChange={(e) => setSandbox(e.target.checked)}
+              className="form-check-input"
+              disabled={isLoading || botLevel > 0}
+            />
+            <label htmlFor="sandbox-mode" className="form-check-label">
+              Sandbox mode (free-form turns and manual card actions)
+            </label>
+          </div>
+
+          <div className="mb-3">
+            <label className="form-label fw-bold">Time Control</label>
+            <select
+              value={isSandbox ? 0 : timeControlIndex}
+              onChange={(e) => setTimeControlIndex(Number(e.target.value))}
+              className="form-select"
+              disabled={isLoading || isSandbox}
+            >
+              {TIME_CONTROLS.map((option, i) => (
+                <option key={option.label} value={i}>
+                  {option.label}
+                </option>
+              ))}
+            </select>
+          </div>
+
+          {timeControl && (
+            <div className="mb-3">
+              <label className="form-label fw-bold">When Time Runs Out</label>
+              <select
+                value={onExpiry}
+                onChange={(e) => setOnExpiry(e.target.value as ExpiryAction)}
+                className="form-select"
+                disabled={isLoading}
+              >
+                <option value="withdraw">Withdraw from the battle</option>
+                <option value="forfeit">Forfeit the game</option>
+              </select>
+            </div>
+          )}
+
+          <div className="mb-3">
+            <label className="form-label fw-bold">Series</label>
+            <select
+              value={bestOf}
+              onChange={(e) => setBestOf(Number(e.target.value))}
+              className="form-select"
+              disabled={isLoading}
+            >
+              {SERIES_LENGTHS.map((length) => (
+                <option key={length} value={length}>
+                  {length === 1 ? "Single game" : `Best of ${length}`}
+                </option>
+              ))}
+            </select>
+          </div>
+
+          <div className="form-check mb-3">
+            <input
+              type="checkbox"
+              id="allow-spectators"
+              checked={allowSpectators}
+              onChange={(e) => setAllowSpectators(e.target.checked)}
+              className="form-check-input"
+              disabled={isLoading}
+            />
+            <label htmlFor="allow-spectators" className="form-check-label">
+              Allow spectators
+            </label>
+          </div>
+
+          {allowSpectators && (
+            <div className="mb-3">
+              <label className="form-label fw-bold">Spectator Delay</label>
+              <select
+                value={spectatorDelay}
+                onChange={(e) => setSpectatorDelay(Number(e.target.value))}
+                className="form-select"
+                disabled={isLoading}
+              >
+                {SPECTATOR_DELAYS.map((delay) => (
+                  <option key={delay} value={delay}>
+                    {delay ? `${delay} moves behind` : "Live"}
+                  </option>
+                ))}
+              </select>
+            </div>
+          )}
+
+          <div className="form-check mb-3">
+            <input
+              type="checkbox"
+              id="private-room"
+              checked={isPrivate}
+              onChange={(e) => setIsPrivate(e.target.checked)}
+              className="form-check-input"
+              disabled={isLoading || botLevel > 0}
+            />
+            <label htmlFor="private-room" className="form-check-label">
+              Private room (join by code only)
+            </label>
+          </div>
+
           <button
             onClick={handleCreateRoom}
             disabled={isLoading}
-            className="btn btn-primary w-100 py-2 mb-4"
+            className="btn btn-primary w-100 py-2 mb-2"
           >
             {isLoading ? "Creating..." : "Create New Room"}
           </button>
 
+          <button
+            onClick={handleQuickMatch}
+            disabled={isLoading || botLevel > 0}
+            className="btn btn-outline-primary w-100 py-2 mb-4"
+          >
+            Quick Match
+          </button>
+
           <div className="d-flex align-items-center mb-4">
             <hr className="flex-grow-1" />
             <span className="px-3 text-muted">OR</span>
             <hr className="flex-grow-1" />
           </div>
 
+          <div className="mb-4">
+            <div className="d-flex align-items-center justify-content-between mb-2">
+              <label className="form-label fw-bold mb-0">Open Rooms</label>
+              <button
+                onClick={handleRefreshLobby}
+                disabled={isLoading}
+                className="btn btn-sm btn-link"
+              >
+                Refresh
+              </button>
+            </div>
+            {lobby.length === 0 ? (
+              <p className="text-muted small mb-0">
+                No rooms are waiting for a player right now.
+              </p>
+            ) : (
+              <ul className="list-group">
+                {lobby.map((room) => (
+                  <li
+                    key={room.id}
+                    className="list-group-item d-flex align-items-center justify-content-between"
+                  >
+                    <div>
+                      <div className="fw-bold">{room.hostName}</div>
+                      <small className="text-muted">
+                        {room.mode === "sandbox" ? "Sandbox" : "Strict"} ·{" "}
+                        {describeTimeControl(room.timeControl)}
+                        {room.bestOf > 1 && ` · Best of ${room.bestOf}`}
+                        {room.allowSpectators && " · Spectators allowed"}
+                      </small>
+                    </div>
+                    <button
+                      onClick={() => handleJoinRoom(room.id)}
+                      disabled={isLoading}
+                      className="btn btn-sm btn-success"
+                    >
+                      Join
+                    </button>
+                  </li>
+                ))}
+              </ul>
+            )}
+          </div>
+
           <div className="mb-3">
             <label className="form-label fw-bold">Room Code</label>
             <input
@@ -174,12 +696,45 @@ export default function RoomLobby({ onGameStart }: RoomLobbyProps) {
           </div>
 
           <button
-            onClick={handleJoinRoom}
+            onClick={() => handleJoinRoom(roomId)}
             disabled={isLoading}
             className="btn btn-success w-100 py-2"
           >
             {isLoading ? "Joining..." : "Join Room"}
           </button>
+
+          <button
+            onClick={handleSpectate}
+            disabled={isLoading}
+            className="btn btn-outline-info w-100 py-2 mt-2"
+          >
+            Watch Game
+          </button>
+
+          <div className="mt-3">
+            <label className="form-label fw-bold">Recovery Code</label>
+            <div className="input-group">
+              <input
+                type="text"
+                value={recoveryCode}
+                onChange={(e) => setRecoveryCode(e.target.value.toUpperCase())}
+                className="form-control font-monospace"
+                placeholder="ABCD-2345"
+                disabled={isLoading}
+              />
+              <button
+                onClick={() => handleReclaimSeat(roomId, recoveryCode)}
+                disabled={isLoading}
+                className="btn btn-outline-success"
+              >
+                Reclaim Seat
+              </button>
+            </div>
+            <small className="text-muted">
+              Lost your tab? Enter the room code above and the recovery code
+              you were given to get your seat back.
+            </small>
+          </div>
         </div>
       </div>
     </div>
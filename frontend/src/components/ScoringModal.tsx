@@ -22,32 +22,10 @@ export default function ScoringModal({
   const [landScore, setLandScore] = useState(0);
   const [seaScore, setSeaScore] = useState(0);
 
-  const hasSubmittedScores = () => {
-    if (!gameState.theaterScores) return false;
-    const isPlayer1 = gameState.player1.id === playerId;
-    return (
-      gameState.theaterScores.air &&
-      (isPlayer1
-        ? gameState.theaterScores.air.player1Total > 0
-        : gameState.theaterScores.air.player2Total > 0)
-    );
-  };
+  const hasSubmittedScores = () =>
+    !!gameState.scoreSubmissions?.[playerId];
 
-  const allScoresSubmitted = () => {
-    if (!gameState.theaterScores) return false;
-    const { air, land, sea } = gameState.theaterScores;
-    return (
-      air &&
-      land &&
-      sea &&
-      air.player1Total > 0 &&
-      air.player2Total > 0 &&
-      land.player1Total > 0 &&
-      land.player2Total > 0 &&
-      sea.player1Total > 0 &&
-      sea.player2Total > 0
-    );
-  };
+  const allScoresSubmitted = () => !!gameState.battleWinnerId;
 
   const handleSubmit = () => {
     const scores: Record<TheaterType, number> = {
@@ -59,17 +37,12 @@ export default function ScoringModal({
   };
 
   const getBattleWinner = () => {
-    if (!gameState.theaterScores || !allScoresSubmitted()) return null;
-    let player1Wins = 0;
-    let player2Wins = 0;
-    const theaters: TheaterType[] = ["air", "land", "sea"];
-    theaters.forEach((theater) => {
-      const score = gameState.theaterScores![theater];
-      if (score.player1Total > score.player2Total) player1Wins++;
-      else if (score.player2Total > score.player1Total) player2Wins++;
-    });
-    if (player1Wins >= 2) return gameState.player1;
-    if (player2Wins >= 2) return gameState.player2;
+    if (gameState.battleWinnerId === gameState.player1.id) {
+      return gameState.player1;
+    }
+    if (gameState.battleWinnerId === gameState.player2.id) {
+      return gameState.player2;
+    }
     return null;
   };
 
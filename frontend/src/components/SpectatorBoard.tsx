@@ -0,0 +1,115 @@
+import { useGameState } from "../hooks";
+import { ErrorAlert, LoadingScreen } from "./shared";
+import TheaterComponent from "./Theater";
+import type { Player, TheaterType } from "../types";
+
+interface SpectatorBoardProps {
+  gameId: string;
+  spectatorId: string;
+  roomId: string;
+  spectatorDelay?: number;
+  onLeave: () => void;
+}
+
+function PlayerSummary({ player, align }: { player: Player; align: string }) {
+  return (
+    <div className={`col text-white ${align}`}>
+      <div className="fw-bold fs-5">{player.name}</div>
+      <small className="text-secondary">
+        {player.score} VP · {player.handCount} cards
+      </small>
+    </div>
+  );
+}
+
+// SpectatorBoard shows a read-only view of a game, with player 2 across
+// the top of each theater and player 1 along the bottom
+export default function SpectatorBoard({
+  gameId,
+  spectatorId,
+  roomId,
+  spectatorDelay,
+  onLeave,
+}: SpectatorBoardProps) {
+  const { gameState, isLoading, error, leaveRoom } = useGameState({
+    gameId,
+    playerId: spectatorId,
+    roomId,
+  });
+
+  if (!gameState) {
+    return <LoadingScreen />;
+  }
+
+  const { player1, player2 } = gameState;
+  const cardsIn = (theater: TheaterType, playerId: string) =>
+    gameState.theaters[theater].cards.filter((pc) => pc.playerId === playerId);
+  const maxCards = (playerId: string) =>
+    Math.max(...gameState.theaterOrder.map((t) => cardsIn(t, playerId).length));
+
+  const current = gameState.currentPlayerId === player1.id ? player1 : player2;
+  const winner = [player1, player2].find((p) => p.id === gameState.winnerId);
+
+  const handleLeave = async () => {
+    if (await leaveRoom()) {
+      onLeave();
+    }
+  };
+
+  return (
+    <div className="game-bg d-flex flex-column" style={{ minHeight: "100vh" }}>
+      <div className="px-3 pt-2 pb-1">
+        <div className="container-fluid">
+          <div className="row bg-dark bg-opacity-75 rounded-3 px-3 py-2 align-items-center g-2">
+            <PlayerSummary player={player2} align="text-start" />
+            <div className="col text-center">
+              <small className="text-secondary text-uppercase d-block mb-1">
+                Battle {gameState.battleNumber}
+              </small>
+              <span className="badge bg-info text-dark">
+                Spectating
+                {spectatorDelay ? ` · ${spectatorDelay} moves behind` : ""}
+              </span>
+              <div className="text-white mt-1">
+                {gameState.phase === "playing" && `${current.name}'s turn`}
+                {gameState.phase === "scoring" && "Scoring the battle"}
+                {gameState.phase === "game_over" &&
+                  (winner ? `${winner.name} wins the game` : "Game over")}
+              </div>
+              <button
+                className="btn btn-sm btn-outline-light mt-1"
+                onClick={handleLeave}
+                disabled={isLoading}
+              >
+                Stop Watching
+              </button>
+            </div>
+            <PlayerSummary player={player1} align="text-end" />
+          </div>
+        </div>
+      </div>
+
+      <ErrorAlert message={error} />
+
+      <div className="flex-grow-1 container-fluid px-4 pb-4 board-scroll">
+        <div className="theater-grid">
+          {gameState.theaterOrder.map((theater) => (
+            <div key={theater} className="theater-column d-flex">
+              <div className="theater-slot flex-grow-1 d-flex flex-column align-items-stretch">
+                <div className="theater-stage flex-shrink-0">
+                  <TheaterComponent
+                    type={theater}
+                    opponentCards={cardsIn(theater, player2.id)}
+                    playerCards={cardsIn(theater, player1.id)}
+                    maxOpponentCards={maxCards(player2.id)}
+                    maxPlayerCards={maxCards(player1.id)}
+                  />
+                </div>
+              </div>
+            </div>
+          ))}
+        </div>
+      </div>
+    </div>
+  );
+}
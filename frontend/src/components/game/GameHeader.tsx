@@ -1,4 +1,6 @@
-import type { Player, GameState } from "../../types";
+import { useEffect, useState } from "react";
+import { apiClient } from "../../api";
+import type { Player, GameState, Clock } from "../../types";
 
 interface GameHeaderProps {
   gameState: GameState;
@@ -6,6 +8,59 @@ interface GameHeaderProps {
   opponent: Player;
   isMyTurn: boolean;
   theaterLabel: string;
+  isLoading: boolean;
+  onLeave: () => void;
+}
+
+// useRemainingMs counts a player's time down locally from when the clock
+// was received, so the display keeps moving between server updates
+function useRemainingMs(clock: Clock | undefined, playerId: string) {
+  const [receivedAt, setReceivedAt] = useState(() => Date.now());
+  const [now, setNow] = useState(() => Date.now());
+
+  useEffect(() => {
+    setReceivedAt(Date.now());
+    setNow(Date.now());
+  }, [clock]);
+
+  const running = clock?.runningPlayerId === playerId;
+  useEffect(() => {
+    if (!running) return;
+    const timer = window.setInterval(() => setNow(Date.now()), 250);
+    return () => window.clearInterval(timer);
+  }, [running]);
+
+  if (!clock) return undefined;
+  const remaining = clock.remainingMs[playerId] ?? 0;
+  return running ? Math.max(0, remaining - (now - receivedAt)) : remaining;
+}
+
+function formatClock(ms: number) {
+  const seconds = Math.ceil(ms / 1000);
+  const minutes = Math.floor(seconds / 60);
+  return `${minutes}:${String(seconds % 60).padStart(2, "0")}`;
+}
+
+interface ClockBadgeProps {
+  clock?: Clock;
+  playerId: string;
+}
+
+function ClockBadge({ clock, playerId }: ClockBadgeProps) {
+  const remaining = useRemainingMs(clock, playerId);
+  if (remaining === undefined) return null;
+
+  const running = clock?.runningPlayerId === playerId;
+  const color = !running
+    ? "bg-secondary"
+    : remaining < 10000
+      ? "bg-danger"
+      : "bg-warning text-dark";
+  return (
+    <span className={`badge ${color} ms-2 font-monospace`}>
+      {formatClock(remaining)}
+    </span>
+  );
 }
 
 export function GameHeader({
@@ -14,7 +69,33 @@ export function GameHeader({
   opponent,
   isMyTurn,
   theaterLabel,
+  isLoading,
+  onLeave,
 }: GameHeaderProps) {
+  const seat = apiClient.savedSeat();
+  const recoveryCode =
+    seat?.roomId === gameState.roomId ? seat.recoveryCode : undefined;
+  const { series } = gameState;
+  // Games finished so far include the current one once it is over
+  const gameNumber =
+    (series?.games.length ?? 0) + (gameState.phase === "game_over" ? 0 : 1);
+
+  // Leaving a series before it is decided concedes it, even between games
+  const seriesOpen = !!series && series.bestOf > 1 && !series.winnerId;
+
+  const handleLeave = () => {
+    if (
+      (gameState.phase === "game_over" && !seriesOpen) ||
+      window.confirm(
+        seriesOpen
+          ? "Leave the game? Your opponent will win the series by default."
+          : "Leave the game? Your opponent will win by default."
+      )
+    ) {
+      onLeave();
+    }
+  };
+
   return (
     <div className="px-3 pt-2 pb-1">
       <div className="container-fluid">
@@ -23,13 +104,19 @@ export function GameHeader({
             <div className="fw-semibold fs-6 text-uppercase text-secondary mb-1">
               Opponent
             </div>
-            <div className="fw-bold fs-5">Player: {opponent.name}</div>
+            <div className="fw-bold fs-5">
+              Player: {opponent.name}
+              <ClockBadge clock={gameState.clock} playerId={opponent.id} />
+            </div>
             <small className="text-secondary">
-              {opponent.score} VP · {opponent.hand.length} cards
+              {opponent.score} VP · {opponent.handCount} cards
             </small>
           </div>
           <div className="col text-center">
             <small className="text-secondary text-uppercase d-block mb-1">
+              {series &&
+                series.bestOf > 1 &&
+                `Best of ${series.bestOf} · Game ${gameNumber} · `}
               Battle {gameState.battleNumber}
             </small>
             <div className="fw-bold fs-5 text-info">{theaterLabel}</div>
@@ -44,15 +131,34 @@ export function GameHeader({
                 )}
               </div>
             )}
+            <button
+              className="btn btn-sm btn-outline-light mt-1"
+              onClick={handleLeave}
+              disabled={isLoading}
+            >
+              Leave Game
+            </button>
           </div>
           <div className="col text-end text-white">
             <div className="fw-semibold fs-6 text-uppercase text-secondary mb-1">
               You
             </div>
-            <div className="fw-bold fs-5">Player: {currentPlayer.name}</div>
+            <div className="fw-bold fs-5">
+              Player: {currentPlayer.name}
+              <ClockBadge clock={gameState.clock} playerId={currentPlayer.id} />
+            </div>
             <small className="text-secondary">
-              {currentPlayer.score} VP · {currentPlayer.hand.length} cards
+              {currentPlayer.score} VP · {currentPlayer.handCount} cards
             </small>
+            {recoveryCode && (
+              <small
+                className="d-block text-secondary"
+                title="Use this code to get back into the game from another tab"
+              >
+                Recovery code:{" "}
+                <span className="font-monospace">{recoveryCode}</span>
+              </small>
+            )}
           </div>
         </div>
       </div>
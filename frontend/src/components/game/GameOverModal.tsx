@@ -1,19 +1,63 @@
-import type { Player } from "../../types";
+import type { BattleResult, Player, Series } from "../../types";
+
+const formatTheaterName = (theater: string) =>
+  theater.charAt(0).toUpperCase() + theater.slice(1);
 
 interface GameOverModalProps {
   currentPlayer: Player;
   opponent: Player;
+  winnerId?: string;
+  forfeitedPlayerId?: string;
+  leftPlayerId?: string;
+  battleResults: BattleResult[];
+  series?: Series;
+  isPlayer1: boolean;
   isLoading: boolean;
   onStartNextGame: () => void;
+  onLeave: () => void;
 }
 
 export function GameOverModal({
   currentPlayer,
   opponent,
+  winnerId,
+  forfeitedPlayerId,
+  leftPlayerId,
+  battleResults,
+  series,
+  isPlayer1,
   isLoading,
   onStartNextGame,
+  onLeave,
 }: GameOverModalProps) {
-  const didWin = currentPlayer.score >= 12;
+  const didWin = winnerId
+    ? winnerId === currentPlayer.id
+    : currentPlayer.score >= 12;
+  // Single games are a series of one and need no series summary
+  const inSeries = series !== undefined && series.bestOf > 1;
+  const seriesOpen = inSeries && !series.winnerId;
+  const nameOf = (id: string) =>
+    id === currentPlayer.id ? "You" : opponent.name;
+
+  // describeBattle says how a battle ended: a withdrawal, or the final
+  // totals of each theater from the viewer's side
+  const describeBattle = (result: BattleResult) => {
+    if (result.withdrewPlayerId) {
+      const cards = result.cardsRemaining;
+      return `${nameOf(result.withdrewPlayerId)} withdrew with ${cards} ${
+        cards === 1 ? "card" : "cards"
+      } left`;
+    }
+    return result.board
+      .map(({ type }) => {
+        const score = result.theaterScores[type];
+        if (!score) return formatTheaterName(type);
+        const own = isPlayer1 ? score.player1Total : score.player2Total;
+        const their = isPlayer1 ? score.player2Total : score.player1Total;
+        return `${formatTheaterName(type)} ${own}–${their}`;
+      })
+      .join(" · ");
+  };
 
   return (
     <div
@@ -26,6 +70,16 @@ export function GameOverModal({
       >
         <div className="card-body text-center p-4">
           <h2 className="card-title text-warning mb-4">Game Over!</h2>
+          {leftPlayerId && leftPlayerId !== currentPlayer.id && (
+            <p className="text-secondary">{opponent.name} left the room.</p>
+          )}
+          {forfeitedPlayerId && (
+            <p className="text-secondary">
+              {forfeitedPlayerId === currentPlayer.id
+                ? "You ran out of time and forfeited the game."
+                : `${opponent.name} ran out of time and forfeited the game.`}
+            </p>
+          )}
           <div className="bg-secondary rounded p-3 mb-2">
             {currentPlayer.name}:{" "}
             <span className="fw-bold text-warning">
@@ -36,6 +90,25 @@ export function GameOverModal({
             {opponent.name}:{" "}
             <span className="fw-bold text-info">{opponent.score} VP</span>
           </div>
+          {battleResults.length > 0 && (
+            <ul className="list-unstyled text-start small mb-4">
+              {battleResults.map((result) => (
+                <li
+                  key={result.battleNumber}
+                  className="border-bottom border-secondary py-1"
+                >
+                  <div>
+                    Battle {result.battleNumber}:{" "}
+                    <span className="fw-semibold">
+                      {nameOf(result.winnerId)}
+                    </span>{" "}
+                    +{result.vp} VP
+                  </div>
+                  <div className="text-secondary">{describeBattle(result)}</div>
+                </li>
+              ))}
+            </ul>
+          )}
           <div className="fs-3 fw-bold">
             {didWin ? (
               <span className="text-success">🎉 You Win! 🎉</span>
@@ -43,13 +116,44 @@ export function GameOverModal({
               <span className="text-danger">You Lose</span>
             )}
           </div>
-          <div className="mt-4 d-grid">
+          {inSeries && (
+            <div className="mt-3">
+              <div className="text-secondary small text-uppercase">
+                Best of {series.bestOf}
+              </div>
+              <div>
+                You {series.wins[currentPlayer.id] ?? 0} –{" "}
+                {series.wins[opponent.id] ?? 0} {opponent.name}
+              </div>
+              {series.winnerId && (
+                <div className="fw-bold text-warning">
+                  {series.winnerId === currentPlayer.id
+                    ? "You win the series!"
+                    : `${opponent.name} wins the series`}
+                </div>
+              )}
+            </div>
+          )}
+          <div className="mt-4 d-grid gap-2">
+            {!leftPlayerId && (
+              <button
+                className="btn btn-warning btn-lg fw-semibold"
+                onClick={onStartNextGame}
+                disabled={isLoading}
+              >
+                {isLoading
+                  ? "Starting..."
+                  : inSeries && !seriesOpen
+                    ? "Rematch"
+                    : "Next Game"}
+              </button>
+            )}
             <button
-              className="btn btn-warning btn-lg fw-semibold"
-              onClick={onStartNextGame}
+              className="btn btn-outline-light"
+              onClick={onLeave}
               disabled={isLoading}
             >
-              {isLoading ? "Starting..." : "Next Game"}
+              {seriesOpen ? "Concede Series" : "Back to Lobby"}
             </button>
           </div>
         </div>
@@ -68,11 +68,11 @@ export function SidePanel({
           >
             <div className="side-title">Deck</div>
             <div className="side-count">
-              {gameState.deck.length} cards remaining
+              {gameState.deckCount} cards remaining
             </div>
             {gameState.phase === "playing" &&
               isMyTurn &&
-              gameState.deck.length > 0 && (
+              gameState.deckCount > 0 && (
                 <button
                   className="btn btn-sm btn-outline-light mt-3"
                   onClick={onDrawCard}
@@ -99,7 +99,7 @@ export function SidePanel({
           >
             <div className="side-title">Trash</div>
             <div className="side-count">
-              {gameState.trash.length} cards destroyed
+              {gameState.trashCount} cards destroyed
             </div>
             <div className="side-hint">Drop a card here to destroy it.</div>
           </div>
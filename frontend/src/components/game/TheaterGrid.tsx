@@ -10,7 +10,11 @@ import type { DropTarget } from "../../hooks";
 import CardComponent from "../Card";
 import TheaterComponent from "../Theater";
 
-const DEFAULT_THEATER_ORDER: TheaterType[] = ["air", "land", "sea"];
+// ScoreInput holds the totals a player is entering for one theater
+export interface ScoreInput {
+  self: string;
+  opponent: string;
+}
 
 const formatTheaterName = (theater: TheaterType) =>
   theater.charAt(0).toUpperCase() + theater.slice(1);
@@ -19,14 +23,15 @@ interface TheaterGridProps {
   theaterOrder: TheaterType[];
   preferredTheater: TheaterType | null;
   isScoringPhase: boolean;
-  playerSubmitted: boolean;
+  scoresEditable: boolean;
+  disputedTheaters: TheaterType[];
   isLoading: boolean;
   isMyTurn: boolean;
   playerId: string;
   opponentName: string;
   theaterScores: Partial<Record<TheaterType, TheaterScore>> | null;
   isPlayer1: boolean;
-  scoreInputs: Record<TheaterType, string>;
+  scoreInputs: Record<TheaterType, ScoreInput>;
   theaters: Record<TheaterType, { cards: PlayedCard[] }>;
   getPlayerCardsInTheater: (
     theater: TheaterType,
@@ -44,14 +49,19 @@ interface TheaterGridProps {
   onTheaterCardDragEnd: () => void;
   onTheaterCardClick: (theater: TheaterType, playedCard: PlayedCard) => void;
   onCardPreview: (card: Card, faceUp: boolean) => void;
-  onScoreInputChange: (theater: TheaterType, value: string) => void;
+  onScoreInputChange: (
+    theater: TheaterType,
+    side: keyof ScoreInput,
+    value: string
+  ) => void;
 }
 
 export function TheaterGrid({
   theaterOrder,
   preferredTheater,
   isScoringPhase,
-  playerSubmitted,
+  scoresEditable,
+  disputedTheaters,
   isLoading,
   isMyTurn,
   opponentName,
@@ -137,17 +147,73 @@ export function TheaterGrid({
               />
             </div>
             {isScoringPhase && (
-              <div className="theater-score-card mt-3">
-                <div className="theater-score-line text-uppercase text-secondary">
-                  {opponentName}
-                </div>
-                <div className="theater-score-value">
-                  {getRecordedScore(theater, false) > 0
-                    ? getRecordedScore(theater, false)
-                    : "—"}
-                </div>
-                {playerSubmitted ? (
+              <div
+                className={`theater-score-card mt-3${
+                  disputedTheaters.includes(theater)
+                    ? " border border-warning"
+                    : ""
+                }`}
+              >
+                {disputedTheaters.includes(theater) && (
+                  <div className="small text-warning mb-1">
+                    Totals disputed
+                  </div>
+                )}
+                {scoresEditable ? (
+                  <>
+                    <div className="theater-score-input">
+                      <label
+                        htmlFor={`score-${theater}-opponent`}
+                        className="form-label form-label-sm text-secondary"
+                      >
+                        {opponentName}
+                      </label>
+                      <input
+                        id={`score-${theater}-opponent`}
+                        type="number"
+                        min="0"
+                        className="form-control form-control-sm"
+                        value={scoreInputs[theater]?.opponent ?? ""}
+                        onChange={(event) =>
+                          onScoreInputChange(
+                            theater,
+                            "opponent",
+                            event.target.value
+                          )
+                        }
+                        disabled={isLoading}
+                        inputMode="numeric"
+                      />
+                    </div>
+                    <div className="theater-score-input mt-2">
+                      <label
+                        htmlFor={`score-${theater}-self`}
+                        className="form-label form-label-sm text-secondary"
+                      >
+                        Your score
+                      </label>
+                      <input
+                        id={`score-${theater}-self`}
+                        type="number"
+                        min="0"
+                        className="form-control form-control-sm"
+                        value={scoreInputs[theater]?.self ?? ""}
+                        onChange={(event) =>
+                          onScoreInputChange(theater, "self", event.target.value)
+                        }
+                        disabled={isLoading}
+                        inputMode="numeric"
+                      />
+                    </div>
+                  </>
+                ) : (
                   <>
+                    <div className="theater-score-line text-uppercase text-secondary">
+                      {opponentName}
+                    </div>
+                    <div className="theater-score-value">
+                      {getRecordedScore(theater, false)}
+                    </div>
                     <div className="theater-score-line text-uppercase text-secondary mt-2">
                       You
                     </div>
@@ -155,27 +221,6 @@ export function TheaterGrid({
                       {getRecordedScore(theater, true)}
                     </div>
                   </>
-                ) : (
-                  <div className="theater-score-input mt-2">
-                    <label
-                      htmlFor={`score-${theater}`}
-                      className="form-label form-label-sm text-secondary"
-                    >
-                      Your score
-                    </label>
-                    <input
-                      id={`score-${theater}`}
-                      type="number"
-                      min="0"
-                      className="form-control form-control-sm"
-                      value={scoreInputs[theater] ?? ""}
-                      onChange={(event) =>
-                        onScoreInputChange(theater, event.target.value)
-                      }
-                      disabled={isLoading}
-                      inputMode="numeric"
-                    />
-                  </div>
                 )}
               </div>
             )}
@@ -189,52 +234,82 @@ export function TheaterGrid({
 interface ScoringActionsPanelProps {
   playerSubmitted: boolean;
   opponentSubmitted: boolean;
+  scoresEditable: boolean;
+  battleDecided: boolean;
+  disputedTheaters: TheaterType[];
   allScoresEntered: boolean;
   isLoading: boolean;
   showStartNextBattle: boolean;
   nextBattleLabel: string;
   onSubmitScores: () => void;
+  onAcceptScores: () => void;
   onStartNextBattle: () => void;
 }
 
 export function ScoringActionsPanel({
   playerSubmitted,
   opponentSubmitted,
+  scoresEditable,
+  battleDecided,
+  disputedTheaters,
   allScoresEntered,
   isLoading,
   showStartNextBattle,
   nextBattleLabel,
   onSubmitScores,
+  onAcceptScores,
   onStartNextBattle,
 }: ScoringActionsPanelProps) {
-  const bothSubmitted = playerSubmitted && opponentSubmitted;
+  const status = (() => {
+    if (battleDecided) return "The battle has been scored.";
+    if (disputedTheaters.length) {
+      return `Totals disagree in ${disputedTheaters
+        .map(formatTheaterName)
+        .join(", ")}. Correct them or accept the server's totals.`;
+    }
+    if (playerSubmitted) {
+      return opponentSubmitted
+        ? "Both players have submitted totals."
+        : "Waiting for opponent to submit totals.";
+    }
+    return "Enter both players' total strength in each theater, then submit.";
+  })();
 
   return (
     <div className="scoring-actions-panel bg-dark bg-opacity-50 rounded-3 p-3">
       <div className="d-flex flex-column flex-lg-row align-items-lg-center gap-3">
-        <div className="flex-grow-1">
-          {playerSubmitted ? (
-            <div className="alert alert-success mb-0 py-2">
-              Your scores are submitted.
-            </div>
-          ) : (
+        <div className="flex-grow-1 d-flex gap-2">
+          {scoresEditable ? (
             <button
               className="btn btn-primary"
               onClick={onSubmitScores}
               disabled={!allScoresEntered || isLoading}
             >
-              {isLoading ? "Submitting..." : "Submit Scores"}
+              {isLoading
+                ? "Submitting..."
+                : playerSubmitted
+                ? "Resubmit Scores"
+                : "Submit Scores"}
+            </button>
+          ) : (
+            !battleDecided && (
+              <div className="alert alert-success mb-0 py-2">
+                Your scores are submitted.
+              </div>
+            )
+          )}
+          {!battleDecided && (
+            <button
+              className="btn btn-outline-light"
+              onClick={onAcceptScores}
+              disabled={isLoading}
+            >
+              Accept Server Totals
             </button>
           )}
         </div>
-        <div className="text-secondary small flex-grow-1">
-          {playerSubmitted
-            ? opponentSubmitted
-              ? "Both players have submitted totals."
-              : "Waiting for opponent to submit totals."
-            : "Enter the total strength for each theater, then submit."}
-        </div>
-        {showStartNextBattle && bothSubmitted && (
+        <div className="text-secondary small flex-grow-1">{status}</div>
+        {showStartNextBattle && battleDecided && (
           <div className="d-flex gap-2">
             <button
               className="btn btn-success"
@@ -256,6 +331,7 @@ export function ScoringActionsPanel({
 interface ScoringResultsPanelProps {
   theaterOrder: TheaterType[];
   theaterScores: Partial<Record<TheaterType, TheaterScore>> | null;
+  battleWinnerId?: string;
   player1: Player;
   player2: Player;
   playerId: string;
@@ -265,32 +341,16 @@ interface ScoringResultsPanelProps {
 export function ScoringResultsPanel({
   theaterOrder,
   theaterScores,
+  battleWinnerId,
   player1,
   player2,
   playerId,
   nextBattleLabel,
 }: ScoringResultsPanelProps) {
-  const battleWinner = (() => {
-    if (!theaterScores) return null;
-
-    let player1Wins = 0;
-    let player2Wins = 0;
-    (DEFAULT_THEATER_ORDER as TheaterType[]).forEach((theater) => {
-      const scores = theaterScores[theater];
-      if (!scores) return;
-      if (scores.player1Total > scores.player2Total) {
-        player1Wins++;
-      } else if (scores.player2Total > scores.player1Total) {
-        player2Wins++;
-      }
-    });
-
-    if (player1Wins >= 2) return player1;
-    if (player2Wins >= 2) return player2;
-    return null;
-  })();
-
-  const didIWin = battleWinner?.id === playerId;
+  const playerName = (id?: string) =>
+    id === player1.id ? player1.name : id === player2.id ? player2.name : "";
+  const battleWinner = playerName(battleWinnerId);
+  const didIWin = battleWinnerId === playerId;
 
   return (
     <div className="scoring-results-panel bg-dark bg-opacity-25 rounded-3 p-3">
@@ -314,21 +374,22 @@ export function ScoringResultsPanel({
                     {player2.name}: {scores?.player2Total ?? 0}
                   </span>
                 </div>
+                {scores?.controllerId && (
+                  <div className="small text-info mt-1">
+                    Controlled by {playerName(scores.controllerId)}
+                  </div>
+                )}
               </div>
             </div>
           );
         })}
       </div>
       <div className="text-center fs-5 mt-3">
-        {battleWinner ? (
-          <span className={didIWin ? "text-success" : "text-danger"}>
-            {didIWin
-              ? "You win the battle! +6 VP"
-              : `${battleWinner.name} wins the battle.`}
-          </span>
-        ) : (
-          <span className="text-warning">Tie - No VP awarded</span>
-        )}
+        <span className={didIWin ? "text-success" : "text-danger"}>
+          {didIWin
+            ? "You win the battle! +6 VP"
+            : `${battleWinner} wins the battle.`}
+        </span>
       </div>
       <div className="text-center text-secondary mt-2">
         {player1.name}: {player1.score} VP · {player2.name}: {player2.score} VP
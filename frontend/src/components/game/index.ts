@@ -11,3 +11,4 @@ export {
   OpponentHand,
   PlayerHand,
 } from "./TheaterGrid";
+export type { ScoreInput } from "./TheaterGrid";
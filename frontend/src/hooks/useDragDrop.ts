@@ -13,6 +13,7 @@ interface UseDragDropOptions {
   isMyTurn: boolean;
   isPlaying: boolean;
   isLoading: boolean;
+  isSandbox: boolean;
   onPlayCard: (
     card: Card,
     theater: TheaterType,
@@ -62,6 +63,7 @@ export function useDragDrop({
   isMyTurn,
   isPlaying,
   isLoading,
+  isSandbox,
   onPlayCard,
   onDestroyCard,
   onManipulateCard,
@@ -78,18 +80,18 @@ export function useDragDrop({
 
       if (dragContext.type === "hand") {
         if (target === "hand" || target === "deck") return false;
-        if (target === "trash") return isMyTurn && isPlaying;
+        if (target === "trash") return isSandbox && isMyTurn && isPlaying;
         return isMyTurn && isPlaying;
       }
 
       if (dragContext.type === "theater") {
-        if (!isMyTurn || !isPlaying) return false;
+        if (!isSandbox || !isMyTurn || !isPlaying) return false;
         return target === "hand" || target === "deck" || target === "trash";
       }
 
       return false;
     },
-    [dragContext, isLoading, isMyTurn, isPlaying, playerId]
+    [dragContext, isLoading, isMyTurn, isPlaying, isSandbox, playerId]
   );
 
   const handleHandCardDragStart = useCallback(
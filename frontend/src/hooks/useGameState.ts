@@ -1,10 +1,30 @@
 import { useCallback, useEffect, useRef, useState } from "react";
-import { apiClient } from "../api";
-import type { Card, GameState, TheaterType } from "../types";
+import { ApiError, apiClient } from "../api";
+import type { Card, GameState, TheaterScore, TheaterType } from "../types";
+
+// Friendlier messages for the errors a player can run into mid-game
+const ERROR_MESSAGES: Record<string, string> = {
+  not_your_turn: "It's not your turn",
+  wrong_phase: "That can't be done right now",
+  ability_pending: "Resolve the pending ability first",
+  already_withdrawn: "The battle has already been withdrawn from",
+  card_not_in_hand: "That card is no longer in your hand",
+  session_replaced: "Your seat was reclaimed in another tab",
+  version_conflict: "The game changed before your move went through",
+};
+
+// Errors that mean our copy of the game is out of date
+const STALE_GAME_CODES = [
+  "version_conflict",
+  "not_your_turn",
+  "wrong_phase",
+  "card_not_in_hand",
+];
 
 interface UseGameStateOptions {
   gameId: string;
   playerId: string;
+  roomId: string;
   pollInterval?: number;
 }
 
@@ -29,14 +49,17 @@ interface UseGameStateReturn {
   endTurn: () => Promise<void>;
   drawCard: () => Promise<void>;
   withdraw: () => Promise<void>;
-  submitScores: (scores: Record<TheaterType, number>) => Promise<void>;
+  submitScores: (scores: Record<TheaterType, TheaterScore>) => Promise<void>;
+  acceptScores: () => Promise<void>;
   startNextBattle: () => Promise<boolean>;
   startNextGame: () => Promise<void>;
+  leaveRoom: () => Promise<boolean>;
 }
 
 export function useGameState({
   gameId,
   playerId,
+  roomId,
   pollInterval = 2000,
 }: UseGameStateOptions): UseGameStateReturn {
   const [gameState, setGameState] = useState<GameState | null>(null);
@@ -55,12 +78,33 @@ export function useGameState({
     }
   }, [gameId]);
 
-  // Poll for game updates
+  const reportError = useCallback(
+    (err: unknown, fallback: string) => {
+      console.error(err);
+      if (!(err instanceof ApiError)) {
+        setError(fallback);
+        return;
+      }
+      setError(ERROR_MESSAGES[err.code] ?? err.message);
+      if (STALE_GAME_CODES.includes(err.code)) {
+        refreshGame();
+      }
+    },
+    [refreshGame]
+  );
+
+  // Stream game updates, falling back to polling without EventSource
   useEffect(() => {
     refreshGame();
-    const interval = setInterval(refreshGame, pollInterval);
-    return () => clearInterval(interval);
-  }, [refreshGame, pollInterval]);
+    if (typeof EventSource === "undefined") {
+      const interval = setInterval(refreshGame, pollInterval);
+      return () => clearInterval(interval);
+    }
+    const source = apiClient.subscribeToGame(gameId, setGameState, () =>
+      setError("This game has been closed")
+    );
+    return () => source.close();
+  }, [refreshGame, pollInterval, gameId]);
 
   const playCard = useCallback(
     async (card: Card, theater: TheaterType, faceUp: boolean) => {
@@ -70,20 +114,19 @@ export function useGameState({
       try {
         const updatedGame = await apiClient.playCard(
           gameId,
-          playerId,
+          gameState.version,
           card.id,
           theater,
           faceUp
         );
         setGameState(updatedGame);
       } catch (err) {
-        console.error(err);
-        setError("Failed to play card");
+        reportError(err, "Failed to play card");
       } finally {
         setIsLoading(false);
       }
     },
-    [gameId, playerId, gameState]
+    [gameId, gameState, reportError]
   );
 
   const destroyCard = useCallback(
@@ -94,18 +137,17 @@ export function useGameState({
       try {
         const updatedGame = await apiClient.destroyCard(
           gameId,
-          playerId,
+          gameState.version,
           card.id
         );
         setGameState(updatedGame);
       } catch (err) {
-        console.error(err);
-        setError("Failed to destroy card");
+        reportError(err, "Failed to destroy card");
       } finally {
         setIsLoading(false);
       }
     },
-    [gameId, playerId, gameState]
+    [gameId, gameState, reportError]
   );
 
   const manipulateCard = useCallback(
@@ -120,26 +162,25 @@ export function useGameState({
       try {
         const updatedGame = await apiClient.manipulateCard(
           gameId,
-          playerId,
+          gameState.version,
           theater,
           cardId,
           action
         );
         setGameState(updatedGame);
       } catch (err) {
-        console.error(err);
         const verb =
           action === "flip"
             ? "flip"
             : action === "destroy"
             ? "destroy"
             : "return";
-        setError(`Failed to ${verb} card`);
+        reportError(err, `Failed to ${verb} card`);
       } finally {
         setIsLoading(false);
       }
     },
-    [gameId, playerId, gameState]
+    [gameId, gameState, reportError]
   );
 
   const endTurn = useCallback(async () => {
@@ -147,30 +188,28 @@ export function useGameState({
     setIsLoading(true);
     setError("");
     try {
-      const updatedGame = await apiClient.endTurn(gameId, playerId);
+      const updatedGame = await apiClient.endTurn(gameId, gameState.version);
       setGameState(updatedGame);
     } catch (err) {
-      setError("Failed to end turn");
-      console.error(err);
+      reportError(err, "Failed to end turn");
     } finally {
       setIsLoading(false);
     }
-  }, [gameId, playerId, gameState]);
+  }, [gameId, gameState, reportError]);
 
   const drawCard = useCallback(async () => {
     if (!gameState) return;
     setIsLoading(true);
     setError("");
     try {
-      const updatedGame = await apiClient.drawCard(gameId, playerId);
+      const updatedGame = await apiClient.drawCard(gameId, gameState.version);
       setGameState(updatedGame);
     } catch (err) {
-      setError("Failed to draw card");
-      console.error(err);
+      reportError(err, "Failed to draw card");
     } finally {
       setIsLoading(false);
     }
-  }, [gameId, playerId, gameState]);
+  }, [gameId, gameState, reportError]);
 
   const withdraw = useCallback(async () => {
     if (!gameState) return;
@@ -182,68 +221,104 @@ export function useGameState({
     setIsLoading(true);
     setError("");
     try {
-      const updatedGame = await apiClient.withdraw(gameId, playerId);
+      const updatedGame = await apiClient.withdraw(gameId, gameState.version);
       setGameState(updatedGame);
     } catch (err) {
-      setError("Failed to withdraw");
-      console.error(err);
+      reportError(err, "Failed to withdraw");
     } finally {
       setIsLoading(false);
     }
-  }, [gameId, playerId, gameState]);
+  }, [gameId, gameState, reportError]);
 
   const submitScores = useCallback(
-    async (scores: Record<TheaterType, number>) => {
+    async (scores: Record<TheaterType, TheaterScore>) => {
       if (!gameState) return;
       setIsLoading(true);
       setError("");
       try {
         const updatedGame = await apiClient.updateScores(
           gameId,
-          playerId,
+          gameState.version,
           scores
         );
         setGameState(updatedGame);
       } catch (err) {
-        setError("Failed to submit scores");
-        console.error(err);
+        reportError(err, "Failed to submit scores");
       } finally {
         setIsLoading(false);
       }
     },
-    [gameId, playerId, gameState]
+    [gameId, gameState, reportError]
   );
 
+  const acceptScores = useCallback(async () => {
+    if (!gameState) return;
+    setIsLoading(true);
+    setError("");
+    try {
+      const updatedGame = await apiClient.acceptScores(
+        gameId,
+        gameState.version
+      );
+      setGameState(updatedGame);
+    } catch (err) {
+      reportError(err, "Failed to accept scores");
+    } finally {
+      setIsLoading(false);
+    }
+  }, [gameId, gameState, reportError]);
+
   const startNextBattle = useCallback(async () => {
+    if (!gameState) return false;
     setIsLoading(true);
     setError("");
     let success = false;
     try {
-      const updatedGame = await apiClient.startNextBattle(gameId);
+      const updatedGame = await apiClient.startNextBattle(
+        gameId,
+        gameState.version
+      );
       setGameState(updatedGame);
       success = true;
     } catch (err) {
-      setError("Failed to start next battle");
-      console.error(err);
+      reportError(err, "Failed to start next battle");
     } finally {
       setIsLoading(false);
     }
     return success;
-  }, [gameId]);
+  }, [gameId, gameState, reportError]);
 
   const startNextGame = useCallback(async () => {
+    if (!gameState) return;
     setIsLoading(true);
     setError("");
     try {
-      const updatedGame = await apiClient.startNextGame(gameId);
+      const updatedGame = await apiClient.startNextGame(
+        gameId,
+        gameState.version
+      );
       setGameState(updatedGame);
     } catch (err) {
-      setError("Failed to start next game");
-      console.error(err);
+      reportError(err, "Failed to start next game");
     } finally {
       setIsLoading(false);
     }
-  }, [gameId]);
+  }, [gameId, gameState, reportError]);
+
+  const leaveRoom = useCallback(async () => {
+    setIsLoading(true);
+    setError("");
+    let success = false;
+    try {
+      await apiClient.leaveRoom(roomId);
+      success = true;
+    } catch (err) {
+      reportError(err, "Failed to leave room");
+    } finally {
+      setIsLoading(false);
+    }
+    return success;
+  }, [roomId, reportError]);
 
   // Auto-advance battle after withdrawal (only the withdrawing player triggers this)
   useEffect(() => {
@@ -285,7 +360,9 @@ export function useGameState({
     drawCard,
     withdraw,
     submitScores,
+    acceptScores,
     startNextBattle,
     startNextGame,
+    leaveRoom,
   };
 }
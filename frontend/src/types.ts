@@ -1,10 +1,26 @@
 export type TheaterType = "air" | "land" | "sea";
 
+export type Ability =
+  | "support"
+  | "air_drop"
+  | "maneuver"
+  | "aerodrome"
+  | "containment"
+  | "reinforce"
+  | "ambush"
+  | "cover_fire"
+  | "disrupt"
+  | "transport"
+  | "escalation"
+  | "redeploy"
+  | "blockade";
+
 export interface Card {
   id: number;
   theater: TheaterType;
   strength: number;
   name: string;
+  ability?: Ability;
 }
 
 export interface PlayedCard {
@@ -18,27 +34,106 @@ export interface Theater {
   cards: PlayedCard[];
 }
 
+export interface PendingAbility {
+  ability: Ability;
+  playerId: string;
+  ownerId: string;
+  sourceCardId: number;
+  theater: TheaterType;
+  step?: number;
+  optional: boolean;
+  revealedCard?: Card;
+}
+
+export interface AbilityChoice {
+  skip?: boolean;
+  cardId?: number;
+  theater?: TheaterType;
+}
+
 export interface Player {
   id: string;
   name: string;
   hand: Card[];
+  handCount: number;
   score: number;
 }
 
 export interface TheaterScore {
   player1Total: number;
   player2Total: number;
+  controllerId?: string;
+}
+
+export interface BattleResult {
+  battleNumber: number;
+  firstPlayerId: string;
+  winnerId: string;
+  vp: number;
+  withdrewPlayerId?: string;
+  cardsRemaining: number;
+  theaterScores: Record<TheaterType, TheaterScore>;
+  board: Theater[];
+}
+
+export interface GameResult {
+  gameNumber: number;
+  winnerId: string;
+  scores: Record<string, number>;
+  battles: number;
+  forfeitedPlayerId?: string;
+  leftPlayerId?: string;
+}
+
+// Series is a best-of-N run of games between the players of a room
+export interface Series {
+  bestOf: number;
+  games: GameResult[];
+  wins: Record<string, number>;
+  winnerId?: string;
 }
 
 export type GamePhase = "waiting" | "playing" | "scoring" | "game_over";
 
+export type GameMode = "strict" | "sandbox";
+
+export type OpponentType = "human" | "bot";
+
+export type ExpiryAction = "withdraw" | "forfeit";
+
+export interface TimeControl {
+  perMoveSeconds?: number;
+  bankSeconds?: number;
+  incrementSeconds?: number;
+  onExpiry?: ExpiryAction;
+}
+
+export interface Clock {
+  remainingMs: Record<string, number>;
+  runningPlayerId?: string;
+}
+
+export interface RoomOptions {
+  mode?: GameMode;
+  opponent?: OpponentType;
+  level?: number;
+  seed?: number;
+  timeControl?: TimeControl;
+  allowSpectators?: boolean;
+  spectatorDelay?: number;
+  private?: boolean;
+  bestOf?: number;
+}
+
 export interface GameState {
   id: string;
   roomId: string;
+  version: number;
   player1: Player;
   player2: Player;
-  deck: Card[];
-  trash: Card[];
+  viewerId?: string;
+  deckCount: number;
+  trashCount: number;
   theaterOrder: TheaterType[];
   theaters: Record<TheaterType, Theater>;
   currentPlayerId: string;
@@ -47,9 +142,31 @@ export interface GameState {
   firstPlayerId: string;
   withdrewPlayerId?: string;
   theaterScores?: Record<TheaterType, TheaterScore>;
+  scoreSubmissions?: Record<string, Record<TheaterType, TheaterScore>>;
+  disputedTheaters?: TheaterType[];
+  battleWinnerId?: string;
+  battleResults?: BattleResult[];
+  series?: Series;
+  seriesHistory?: Series[];
+  pendingAbilities?: PendingAbility[];
+  airDropPlayerId?: string;
+  airDropReady?: boolean;
+  extraTurnPlayerId?: string;
+  mode: GameMode;
+  timeControl?: TimeControl;
+  clock?: Clock;
+  winnerId?: string;
+  forfeitedPlayerId?: string;
+  leftPlayerId?: string;
+  spectating?: boolean;
 }
 
-export type RoomStatus = "waiting" | "full" | "playing";
+export type RoomStatus =
+  | "waiting"
+  | "full"
+  | "playing"
+  | "closed"
+  | "abandoned";
 
 export interface Room {
   id: string;
@@ -57,15 +174,78 @@ export interface Room {
   player2?: Player;
   gameId?: string;
   status: RoomStatus;
+  options: RoomOptions;
+  leftPlayerId?: string;
+  updatedAt: string;
+  spectatorCount: number;
 }
 
 export interface CreateRoomResponse {
   room: Room;
   playerId: string;
+  token: string;
+  recoveryCode: string;
 }
 
 export interface JoinRoomResponse {
   room: Room;
   game: GameState;
   playerId: string;
+  token: string;
+  recoveryCode: string;
+}
+
+export interface ReclaimSeatResponse {
+  room: Room;
+  game?: GameState;
+  playerId: string;
+  token: string;
+  recoveryCode: string;
+}
+
+export interface SpectateResponse {
+  room: Room;
+  game?: GameState;
+  spectatorId: string;
+  token: string;
+}
+
+// ErrorResponse is the body of every error response from the API
+export interface ErrorResponse {
+  code: string;
+  message: string;
+}
+
+// LobbyRoom is an open room waiting for a second player
+export interface LobbyRoom {
+  id: string;
+  hostName: string;
+  mode: GameMode;
+  timeControl?: TimeControl;
+  bestOf: number;
+  allowSpectators?: boolean;
+  spectatorDelay?: number;
+  spectatorCount: number;
+  waitingSince: string;
+}
+
+export type MatchStatus = "waiting" | "matched";
+
+// MatchResponse is a quick-match ticket. The seat is filled in once
+// another player has been found.
+export interface MatchResponse {
+  ticketId: string;
+  status: MatchStatus;
+  room?: Room;
+  game?: GameState;
+  playerId?: string;
+  token?: string;
+  recoveryCode?: string;
+}
+
+// SavedSeat is kept in local storage so a player who closes the tab can
+// reclaim their seat
+export interface SavedSeat {
+  roomId: string;
+  recoveryCode: string;
 }
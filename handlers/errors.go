@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/dfturn/alns/bot"
+	"github.com/dfturn/alns/service"
+)
+
+var (
+	errInvalidVersion       = errors.New("invalid If-Match version")
+	errInvalidEventIndex    = errors.New("invalid event index")
+	errInvalidLastEventID   = errors.New("invalid last event ID")
+	errStreamingUnsupported = errors.New("streaming unsupported")
+)
+
+// ErrorResponse is the body of every error response. Code is stable and
+// meant for clients to act on; Message is for people and may change.
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// errorCodes gives the status and code of each error the API knows about.
+// The first entry the error matches with errors.Is wins.
+var errorCodes = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{errMissingSession, http.StatusUnauthorized, "missing_session"},
+	{errInvalidSession, http.StatusUnauthorized, "invalid_session"},
+	{errSessionReplaced, http.StatusUnauthorized, "session_replaced"},
+	{errSpectating, http.StatusForbidden, "spectator"},
+	{errWrongGame, http.StatusForbidden, "wrong_game"},
+	{errWrongRoom, http.StatusForbidden, "wrong_room"},
+	{errInvalidVersion, http.StatusBadRequest, "invalid_version"},
+	{errInvalidEventIndex, http.StatusBadRequest, "invalid_event_index"},
+	{errInvalidLastEventID, http.StatusBadRequest, "invalid_last_event_id"},
+	{errStreamingUnsupported, http.StatusInternalServerError, "streaming_unsupported"},
+
+	{service.ErrGameNotFound, http.StatusNotFound, "game_not_found"},
+	{service.ErrRoomNotFound, http.StatusNotFound, "room_not_found"},
+	{service.ErrTicketNotFound, http.StatusNotFound, "ticket_not_found"},
+	{service.ErrEventNotFound, http.StatusNotFound, "event_not_found"},
+	{service.ErrNotFound, http.StatusNotFound, "not_found"},
+
+	{service.ErrNotInGame, http.StatusForbidden, "not_in_game"},
+	{service.ErrNotInRoom, http.StatusForbidden, "not_in_room"},
+	{service.ErrNotRoomCreator, http.StatusForbidden, "not_room_creator"},
+	{service.ErrInvalidRecoveryCode, http.StatusForbidden, "invalid_recovery_code"},
+	{service.ErrSpectatorsNotAllowed, http.StatusForbidden, "spectators_not_allowed"},
+
+	{service.ErrVersionConflict, http.StatusConflict, "version_conflict"},
+	{service.ErrNotYourTurn, http.StatusConflict, "not_your_turn"},
+	{service.ErrWrongPhase, http.StatusConflict, "wrong_phase"},
+	{service.ErrAbilityPending, http.StatusConflict, "ability_pending"},
+	{service.ErrAlreadyWithdrawn, http.StatusConflict, "already_withdrawn"},
+	{service.ErrRoomUnavailable, http.StatusConflict, "room_unavailable"},
+	{service.ErrRoomClosed, http.StatusConflict, "room_closed"},
+	{service.ErrTicketMatched, http.StatusConflict, "ticket_matched"},
+	{service.ErrTimeNotUp, http.StatusConflict, "time_not_up"},
+
+	{service.ErrCardNotInHand, http.StatusUnprocessableEntity, "card_not_in_hand"},
+	{service.ErrInvalidPlacement, http.StatusUnprocessableEntity, "invalid_placement"},
+	{service.ErrUnknownTheater, http.StatusUnprocessableEntity, "unknown_theater"},
+	{service.ErrInvalidMove, http.StatusUnprocessableEntity, "invalid_move"},
+	{service.ErrInvalidScores, http.StatusUnprocessableEntity, "invalid_scores"},
+	{service.ErrWrongMode, http.StatusUnprocessableEntity, "wrong_mode"},
+
+	{service.ErrInvalidOptions, http.StatusBadRequest, "invalid_options"},
+	{bot.ErrUnknownLevel, http.StatusBadRequest, "unknown_bot_level"},
+	{service.ErrUnknownAction, http.StatusBadRequest, "unknown_action"},
+
+	{service.ErrCorruptMoveLog, http.StatusInternalServerError, "corrupt_move_log"},
+}
+
+// statusCodes are the codes of errors the API does not know about, by the
+// status they were given
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          "bad_request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "conflict",
+	http.StatusInternalServerError: "internal_error",
+}
+
+// serviceErrorStatus picks the HTTP status and code for an error. Errors
+// the API does not know about get the fallback status.
+func serviceErrorStatus(err error, fallback int) (int, string) {
+	for _, known := range errorCodes {
+		if errors.Is(err, known.err) {
+			return known.status, known.code
+		}
+	}
+
+	code, ok := statusCodes[fallback]
+	if !ok {
+		code = "error"
+	}
+	return fallback, code
+}
+
+// writeError responds with err as an ErrorResponse
+func writeError(w http.ResponseWriter, err error, fallback int) {
+	status, code := serviceErrorStatus(err, fallback)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Code: code, Message: err.Error()})
+}
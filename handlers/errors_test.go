@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/dfturn/alns/bot"
+	"github.com/dfturn/alns/service"
+)
+
+func TestServiceErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{service.ErrNotInGame, http.StatusForbidden, "not_in_game"},
+		{fmt.Errorf("%w: game is over", service.ErrWrongPhase), http.StatusConflict, "wrong_phase"},
+		{service.ErrVersionConflict, http.StatusConflict, "version_conflict"},
+		{service.ErrTimeNotUp, http.StatusConflict, "time_not_up"},
+		{fmt.Errorf("%w %q", service.ErrUnknownAction, "dance"), http.StatusBadRequest, "unknown_action"},
+		{fmt.Errorf("%w 9", bot.ErrUnknownLevel), http.StatusBadRequest, "unknown_bot_level"},
+		{fmt.Errorf("%w: no initial state", service.ErrCorruptMoveLog), http.StatusInternalServerError, "corrupt_move_log"},
+		{errInvalidVersion, http.StatusBadRequest, "invalid_version"},
+		{fmt.Errorf("decode: unexpected EOF"), http.StatusBadRequest, "bad_request"},
+	}
+
+	for _, tt := range tests {
+		status, code := serviceErrorStatus(tt.err, http.StatusBadRequest)
+		if status != tt.status || code != tt.code {
+			t.Errorf("serviceErrorStatus(%v) = %d %s, want %d %s", tt.err, status, code, tt.status, tt.code)
+		}
+	}
+}
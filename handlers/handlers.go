@@ -2,32 +2,44 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dfturn/alns/bot"
 	"github.com/dfturn/alns/models"
 	"github.com/dfturn/alns/service"
 	"github.com/gorilla/mux"
 )
 
 type Handler struct {
-	gameService *service.GameService
+	gameService   *service.GameService
+	sessionSecret []byte
 }
 
-func NewHandler(gameService *service.GameService) *Handler {
+// NewHandler creates the HTTP handlers. Session tokens are signed with
+// sessionSecret.
+func NewHandler(gameService *service.GameService, sessionSecret []byte) *Handler {
 	return &Handler{
-		gameService: gameService,
+		gameService:   gameService,
+		sessionSecret: sessionSecret,
 	}
 }
 
 // CreateRoomRequest is the request to create a new room
 type CreateRoomRequest struct {
-	PlayerName string `json:"playerName"`
+	PlayerName string             `json:"playerName"`
+	Options    models.RoomOptions `json:"options"`
 }
 
 // CreateRoomResponse is the response for creating a room
 type CreateRoomResponse struct {
-	Room     *models.Room `json:"room"`
-	PlayerID string       `json:"playerId"`
+	Room         *models.Room `json:"room"`
+	PlayerID     string       `json:"playerId"`
+	Token        string       `json:"token"`        // Send as "Authorization: Bearer <token>"
+	RecoveryCode string       `json:"recoveryCode"` // Reclaims the seat if the token is lost
 }
 
 // JoinRoomRequest is the request to join a room
@@ -37,71 +49,101 @@ type JoinRoomRequest struct {
 
 // JoinRoomResponse is the response for joining a room
 type JoinRoomResponse struct {
-	Room     *models.Room      `json:"room"`
-	Game     *models.GameState `json:"game"`
-	PlayerID string            `json:"playerId"`
+	Room         *models.Room     `json:"room"`
+	Game         *models.GameView `json:"game"`
+	PlayerID     string           `json:"playerId"`
+	Token        string           `json:"token"`
+	RecoveryCode string           `json:"recoveryCode"`
 }
 
-// PlayCardRequest is the request to play a card
-type PlayCardRequest struct {
-	PlayerID string             `json:"playerId"`
-	CardID   int                `json:"cardId"`
-	Theater  models.TheaterType `json:"theater"`
-	FaceUp   bool               `json:"faceUp"`
+// ReclaimSeatRequest is the request to reclaim a seat in a room
+type ReclaimSeatRequest struct {
+	RecoveryCode string `json:"recoveryCode"`
 }
 
-// WithdrawRequest is the request to withdraw
-type WithdrawRequest struct {
-	PlayerID string `json:"playerId"`
+// ReclaimSeatResponse is the response for reclaiming a seat. Game is only
+// set once the room's game has started.
+type ReclaimSeatResponse struct {
+	Room         *models.Room     `json:"room"`
+	Game         *models.GameView `json:"game,omitempty"`
+	PlayerID     string           `json:"playerId"`
+	Token        string           `json:"token"`
+	RecoveryCode string           `json:"recoveryCode"` // Replaces the code that was used
 }
 
-// UpdateScoresRequest is the request to update theater scores
-type UpdateScoresRequest struct {
-	PlayerID string                     `json:"playerId"`
-	Scores   map[models.TheaterType]int `json:"scores"`
+// PlayCardRequest is the request to play a card
+type PlayCardRequest struct {
+	CardID  int                `json:"cardId"`
+	Theater models.TheaterType `json:"theater"`
+	FaceUp  bool               `json:"faceUp"`
 }
 
-// EndTurnRequest is the request to end turn
-type EndTurnRequest struct {
-	PlayerID string `json:"playerId"`
+// UpdateScoresRequest is the request to update theater scores
+type UpdateScoresRequest struct {
+	Scores map[models.TheaterType]models.TheaterScore `json:"scores"` // Totals for both players
 }
 
-// DrawCardRequest is the request to draw a card
-type DrawCardRequest struct {
-	PlayerID string `json:"playerId"`
+// ResolveAbilityRequest is the request to resolve a pending card ability
+type ResolveAbilityRequest struct {
+	Choice models.AbilityChoice `json:"choice"`
 }
 
 // ManipulateCardRequest is the request to manipulate a card
 type ManipulateCardRequest struct {
-	PlayerID string             `json:"playerId"`
-	Theater  models.TheaterType `json:"theater"`
-	CardID   int                `json:"cardId"`
-	Action   string             `json:"action"` // "flip", "destroy", or "return"
+	Theater models.TheaterType `json:"theater"`
+	CardID  int                `json:"cardId"`
+	Action  string             `json:"action"` // "flip", "destroy", or "return"
 }
 
 // DestroyCardRequest is the request to destroy a card from hand
 type DestroyCardRequest struct {
-	PlayerID string `json:"playerId"`
-	CardID   int    `json:"cardId"`
+	CardID int `json:"cardId"`
 }
 
 // CreateRoom handles POST /api/rooms
 func (h *Handler) CreateRoom(w http.ResponseWriter, r *http.Request) {
 	var req CreateRoomRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.Options.Opponent == models.OpponentBot {
+		if _, err := bot.New(req.Options.Level, 0); err != nil {
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	room, err := h.gameService.CreateRoom(req.PlayerName, req.Options)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	room, err := h.gameService.CreateRoom(req.PlayerName)
+	// A bot takes the second seat right away, so the game starts now. A
+	// room whose bot could not join is closed rather than left waiting.
+	if room.Options.Opponent == models.OpponentBot {
+		seated, err := bot.Join(h.gameService, room.ID)
+		if err != nil {
+			h.gameService.CloseRoom(room.ID, room.Player1.ID)
+			writeError(w, err, http.StatusInternalServerError)
+			return
+		}
+		room = seated
+	}
+
+	token, err := h.issueToken(room, room.Player1.ID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	resp := CreateRoomResponse{
-		Room:     room,
-		PlayerID: room.Player1.ID,
+		Room:         service.NewRoomView(room),
+		PlayerID:     room.Player1.ID,
+		Token:        token,
+		RecoveryCode: room.Seats[room.Player1.ID].RecoveryCode,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -115,20 +157,114 @@ func (h *Handler) JoinRoom(w http.ResponseWriter, r *http.Request) {
 
 	var req JoinRoomRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
 	room, game, err := h.gameService.JoinRoom(roomID, req.PlayerName)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.issueToken(room, room.Player2.ID)
+	if err != nil {
+		writeError(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	resp := JoinRoomResponse{
-		Room:     room,
-		Game:     game,
-		PlayerID: room.Player2.ID,
+		Room:         service.NewRoomView(room),
+		Game:         service.NewGameView(game, room.Player2.ID),
+		PlayerID:     room.Player2.ID,
+		Token:        token,
+		RecoveryCode: room.Seats[room.Player2.ID].RecoveryCode,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// SpectateResponse is the response for starting to watch a room. Game is
+// only set once the room's game has started.
+type SpectateResponse struct {
+	Room        *models.Room     `json:"room"`
+	Game        *models.GameView `json:"game,omitempty"`
+	SpectatorID string           `json:"spectatorId"`
+	Token       string           `json:"token"`
+}
+
+// Spectate handles POST /api/rooms/:id/spectate
+func (h *Handler) Spectate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID := vars["id"]
+
+	room, spectatorID, err := h.gameService.Spectate(roomID)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.issueToken(room, spectatorID)
+	if err != nil {
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	resp := SpectateResponse{
+		Room:        service.NewRoomView(room),
+		SpectatorID: spectatorID,
+		Token:       token,
+	}
+	if room.GameID != "" {
+		resp.Game, err = h.gameService.SpectatorView(room.GameID)
+		if err != nil {
+			writeError(w, err, http.StatusNotFound)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ReclaimSeat handles POST /api/rooms/:id/reclaim
+// The session the seat had before stops working.
+func (h *Handler) ReclaimSeat(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID := vars["id"]
+
+	var req ReclaimSeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	room, playerID, err := h.gameService.ReclaimSeat(roomID, req.RecoveryCode)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.issueToken(room, playerID)
+	if err != nil {
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	resp := ReclaimSeatResponse{
+		Room:         service.NewRoomView(room),
+		PlayerID:     playerID,
+		Token:        token,
+		RecoveryCode: room.Seats[playerID].RecoveryCode,
+	}
+	if room.GameID != "" {
+		game, err := h.gameService.GetGame(room.GameID)
+		if err != nil {
+			writeError(w, err, http.StatusNotFound)
+			return
+		}
+		resp.Game = service.NewGameView(game, playerID)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -142,27 +278,256 @@ func (h *Handler) GetRoom(w http.ResponseWriter, r *http.Request) {
 
 	room, err := h.gameService.GetRoom(roomID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(service.NewRoomView(room))
+}
+
+// LeaveRoom handles POST /api/rooms/:id/leave
+func (h *Handler) LeaveRoom(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID := vars["id"]
+
+	playerID, ok := h.authorizeRoom(w, r, roomID)
+	if !ok {
+		return
+	}
+
+	room, err := h.gameService.LeaveRoom(roomID, playerID)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(service.NewRoomView(room))
+}
+
+// CloseRoom handles POST /api/rooms/:id/close
+func (h *Handler) CloseRoom(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roomID := vars["id"]
+
+	playerID, ok := h.authorizeRoom(w, r, roomID)
+	if !ok {
+		return
+	}
+
+	room, err := h.gameService.CloseRoom(roomID, playerID)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(service.NewRoomView(room))
+}
+
+// ListLobby handles GET /api/lobby
+func (h *Handler) ListLobby(w http.ResponseWriter, r *http.Request) {
+	lobby, err := h.gameService.ListLobby()
+	if err != nil {
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lobby)
+}
+
+// QuickMatchRequest is the request to join the quick-match queue. Players
+// are paired on mode, series length and time control; the room takes the
+// spectator options of whoever was waiting. Bots and seeds are refused.
+type QuickMatchRequest struct {
+	PlayerName string             `json:"playerName"`
+	Options    models.RoomOptions `json:"options"`
+}
+
+// MatchResponse is the state of a quick-match ticket. The room, game,
+// seat and token are only set once the ticket is matched.
+type MatchResponse struct {
+	TicketID     string             `json:"ticketId"` // Keep secret; it hands out the seat
+	Status       models.MatchStatus `json:"status"`
+	Room         *models.Room       `json:"room,omitempty"`
+	Game         *models.GameView   `json:"game,omitempty"`
+	PlayerID     string             `json:"playerId,omitempty"`
+	Token        string             `json:"token,omitempty"`
+	RecoveryCode string             `json:"recoveryCode,omitempty"`
+}
+
+// QuickMatch handles POST /api/matchmaking
+func (h *Handler) QuickMatch(w http.ResponseWriter, r *http.Request) {
+	var req QuickMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := h.gameService.QuickMatch(req.PlayerName, req.Options)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	h.writeMatch(w, ticket)
+}
+
+// GetMatch handles GET /api/matchmaking/:id
+// Waiting players poll it; a ticket nobody polls drops out of the queue.
+func (h *Handler) GetMatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ticketID := vars["id"]
+
+	ticket, err := h.gameService.GetMatchTicket(ticketID)
+	if err != nil {
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+	h.writeMatch(w, ticket)
+}
+
+// CancelMatch handles DELETE /api/matchmaking/:id
+func (h *Handler) CancelMatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ticketID := vars["id"]
+
+	if err := h.gameService.CancelMatch(ticketID); err != nil {
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeMatch responds with a ticket, handing out the seat it was matched to
+func (h *Handler) writeMatch(w http.ResponseWriter, ticket *models.MatchTicket) {
+	resp := MatchResponse{
+		TicketID: ticket.ID,
+		Status:   ticket.Status,
+	}
+
+	if ticket.Status == models.MatchFound {
+		room, err := h.gameService.GetRoom(ticket.RoomID)
+		if err != nil {
+			writeError(w, err, http.StatusNotFound)
+			return
+		}
+		game, err := h.gameService.GetGame(room.GameID)
+		if err != nil {
+			writeError(w, err, http.StatusNotFound)
+			return
+		}
+		token, err := h.issueToken(room, ticket.PlayerID)
+		if err != nil {
+			writeError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		resp.Room = service.NewRoomView(room)
+		resp.Game = service.NewGameView(game, ticket.PlayerID)
+		resp.PlayerID = ticket.PlayerID
+		resp.Token = token
+		resp.RecoveryCode = room.Seats[ticket.PlayerID].RecoveryCode
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(room)
+	json.NewEncoder(w).Encode(resp)
 }
 
 // GetGame handles GET /api/games/:id
+// Spectators get the room's spectator view.
 func (h *Handler) GetGame(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
+	playerID, spectating, ok := h.authorizeViewer(w, sessionToken(r), gameID)
+	if !ok {
+		return
+	}
+
+	if spectating {
+		view, err := h.gameService.SpectatorView(gameID)
+		if err != nil {
+			writeError(w, err, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(view)
+		return
+	}
+
 	game, err := h.gameService.GetGame(gameID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err, http.StatusNotFound)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
+}
+
+// GetHistory handles GET /api/games/:id/history
+func (h *Handler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID := vars["id"]
+
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	game, err := h.gameService.GetGame(gameID)
+	if err != nil {
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+
+	events, err := h.gameService.GetEvents(gameID)
+	if err != nil {
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(service.NewEventLogView(game, events, playerID))
+}
+
+// ReplayGame handles GET /api/games/:id/replay/:index
+// Once the game is over the replayed state is fully revealed.
+func (h *Handler) ReplayGame(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID := vars["id"]
+
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	index, err := strconv.Atoi(vars["index"])
+	if err != nil {
+		writeError(w, errInvalidEventIndex, http.StatusBadRequest)
+		return
+	}
+
+	game, err := h.gameService.GetGame(gameID)
+	if err != nil {
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+
+	replayed, err := h.gameService.ReplayGame(gameID, index)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if game.Phase == models.PhaseGameOver {
+		json.NewEncoder(w).Encode(service.NewRevealedGameView(replayed, playerID))
+		return
+	}
+	json.NewEncoder(w).Encode(service.NewGameView(replayed, playerID))
 }
 
 // PlayCard handles POST /api/games/:id/play-card
@@ -170,20 +535,31 @@ func (h *Handler) PlayCard(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
 	var req PlayCardRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	game, err := h.gameService.PlayCard(gameID, req.PlayerID, req.CardID, req.Theater, req.FaceUp)
+	game, err := h.gameService.PlayCard(gameID, playerID, version, req.CardID, req.Theater, req.FaceUp)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
 }
 
 // Withdraw handles POST /api/games/:id/withdraw
@@ -191,20 +567,25 @@ func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
-	var req WithdrawRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	game, err := h.gameService.Withdraw(gameID, req.PlayerID)
+	game, err := h.gameService.Withdraw(gameID, playerID, version)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
 }
 
 // UpdateScores handles POST /api/games/:id/update-scores
@@ -212,20 +593,57 @@ func (h *Handler) UpdateScores(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
 	var req UpdateScoresRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	game, err := h.gameService.UpdateTheaterScores(gameID, playerID, version, req.Scores)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
+}
+
+// AcceptScores handles POST /api/games/:id/accept-scores
+func (h *Handler) AcceptScores(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID := vars["id"]
+
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	game, err := h.gameService.UpdateTheaterScores(gameID, req.PlayerID, req.Scores)
+	game, err := h.gameService.AcceptServerScores(gameID, playerID, version)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
 }
 
 // StartNextBattle handles POST /api/games/:id/next-battle
@@ -233,14 +651,25 @@ func (h *Handler) StartNextBattle(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
-	game, err := h.gameService.StartNextBattle(gameID)
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	game, err := h.gameService.StartNextBattle(gameID, playerID, version)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
 }
 
 // StartNextGame handles POST /api/games/:id/next-game
@@ -248,14 +677,25 @@ func (h *Handler) StartNextGame(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
-	game, err := h.gameService.StartNextGame(gameID)
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	game, err := h.gameService.StartNextGame(gameID, playerID, version)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
 }
 
 // EndTurn handles POST /api/games/:id/end-turn
@@ -263,83 +703,251 @@ func (h *Handler) EndTurn(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
-	var req EndTurnRequest
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	game, err := h.gameService.EndTurn(gameID, playerID, version)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
+}
+
+// ResolveAbility handles POST /api/games/:id/resolve-ability
+func (h *Handler) ResolveAbility(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID := vars["id"]
+
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var req ResolveAbilityRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	game, err := h.gameService.EndTurn(gameID, req.PlayerID)
+	game, err := h.gameService.ResolveAbility(gameID, playerID, version, req.Choice)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
 }
 
-// DrawCard handles POST /api/games/:id/draw-card
+// DrawCard handles POST /api/games/:id/draw-card (sandbox mode)
 func (h *Handler) DrawCard(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
-	var req DrawCardRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
 		return
 	}
 
-	game, err := h.gameService.DrawCard(gameID, req.PlayerID)
+	version, err := expectedVersion(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	game, err := h.gameService.DrawCard(gameID, playerID, version)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
 }
 
-// ManipulateCard handles POST /api/games/:id/manipulate-card
+// ManipulateCard handles POST /api/games/:id/manipulate-card (sandbox mode)
 func (h *Handler) ManipulateCard(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
 	var req ManipulateCardRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	game, err := h.gameService.ManipulateCard(gameID, req.PlayerID, req.Theater, req.CardID, req.Action)
+	game, err := h.gameService.ManipulateCard(gameID, playerID, version, req.Theater, req.CardID, req.Action)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
 }
 
-// DestroyCard handles POST /api/games/:id/destroy-card
+// DestroyCard handles POST /api/games/:id/destroy-card (sandbox mode)
 func (h *Handler) DestroyCard(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
+	playerID, ok := h.authorize(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
 	var req DestroyCardRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	game, err := h.gameService.DestroyCard(gameID, req.PlayerID, req.CardID)
+	game, err := h.gameService.DestroyCard(gameID, playerID, version, req.CardID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(service.NewGameView(game, playerID))
+}
+
+// expectedVersion reads the optional If-Match header carrying the game
+// version the client last saw. Zero means the client did not send one.
+func expectedVersion(r *http.Request) (int, error) {
+	header := strings.Trim(r.Header.Get("If-Match"), `"`)
+	if header == "" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(header)
+	if err != nil {
+		return 0, errInvalidVersion
+	}
+	return version, nil
+}
+
+// eventsHeartbeat keeps idle event streams from being closed by proxies
+const eventsHeartbeat = 15 * time.Second
+
+// GameEvents handles GET /api/games/:id/events
+// It streams the player's view as Server-Sent Events. Each event ID is the
+// game version, so reconnecting clients resume via Last-Event-ID (or a
+// lastVersion query parameter). A "closed" event ends the stream when the
+// game is deleted; streams of a session that was replaced just end.
+// Spectators are streamed the room's spectator view.
+func (h *Handler) GameEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID := vars["id"]
+
+	token := streamToken(r)
+	playerID, spectating, ok := h.authorizeViewer(w, token, gameID)
+	if !ok {
+		return
+	}
+
+	lastVersion := 0
+	lastSeen := r.Header.Get("Last-Event-ID")
+	if lastSeen == "" {
+		lastSeen = r.URL.Query().Get("lastVersion")
+	}
+	if lastSeen != "" {
+		v, err := strconv.Atoi(lastSeen)
+		if err != nil {
+			writeError(w, errInvalidLastEventID, http.StatusBadRequest)
+			return
+		}
+		lastVersion = v
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, errStreamingUnsupported, http.StatusInternalServerError)
+		return
+	}
+
+	views, cancel, err := h.gameService.Subscribe(gameID, playerID, lastVersion)
+	if err != nil {
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	claims, _ := h.parseToken(token)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if !h.sessionCurrent(claims) {
+				return
+			}
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case view, ok := <-views:
+			if !ok {
+				fmt.Fprint(w, "event: closed\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+			if !h.sessionCurrent(claims) {
+				return
+			}
+			if spectating {
+				if view, err = h.gameService.SpectatorView(gameID); err != nil {
+					return
+				}
+			}
+			data, err := json.Marshal(view)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "id: %d\nevent: game\ndata: %s\n\n", view.Version, data)
+			flusher.Flush()
+		}
+	}
 }
 
 // CORS middleware
@@ -347,7 +955,7 @@ func (h *Handler) EnableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	router := newTestRouter()
+	seats := startTestGame(t, router)
+	path := "/api/games/" + seats.gameID + "/withdraw"
+
+	w := request(router, "POST", path, seats.currentToken, nil, http.Header{"If-Match": {"soon"}})
+	checkError(t, w, http.StatusBadRequest, "invalid_version")
+
+	current := strconv.Quote(strconv.Itoa(seats.version))
+	w = request(router, "POST", path, seats.currentToken, nil, http.Header{"If-Match": {current}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("withdraw at the current version = %d %s", w.Code, w.Body)
+	}
+	var view models.GameView
+	if err := json.NewDecoder(w.Body).Decode(&view); err != nil {
+		t.Fatal(err)
+	}
+	if view.Version != seats.version+1 {
+		t.Errorf("version after withdrawing = %d, want %d", view.Version, seats.version+1)
+	}
+
+	// The same request again is now a version behind
+	w = request(router, "POST", path, seats.currentToken, nil, http.Header{"If-Match": {current}})
+	checkError(t, w, http.StatusConflict, "version_conflict")
+}
+
+func TestMissingIfMatchSkipsVersionCheck(t *testing.T) {
+	router := newTestRouter()
+	seats := startTestGame(t, router)
+
+	w := request(router, "POST", "/api/games/"+seats.gameID+"/withdraw", seats.currentToken, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Errorf("withdraw without If-Match = %d %s, want 200", w.Code, w.Body)
+	}
+}
+
+// openStream connects to a game's event stream, resuming after lastEventID
+func openStream(t *testing.T, server *httptest.Server, gameID, token, lastEventID string) *bufio.Reader {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	r, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/games/"+gameID+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	r.Header.Set("Last-Event-ID", lastEventID)
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("events = %d, want 200", resp.StatusCode)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+// nextEventID reads a stream up to its next event and returns the event's ID
+func nextEventID(t *testing.T, stream *bufio.Reader) int {
+	t.Helper()
+	for {
+		line, err := stream.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading the event stream: %v", err)
+		}
+		if id, ok := strings.CutPrefix(strings.TrimSpace(line), "id: "); ok {
+			version, err := strconv.Atoi(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return version
+		}
+	}
+}
+
+func TestEventsResumeAfterLastEventID(t *testing.T) {
+	router := newTestRouter()
+	seats := startTestGame(t, router)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close) // After the streams are closed
+
+	current := openStream(t, server, seats.gameID, seats.tokens[0], strconv.Itoa(seats.version))
+	behind := openStream(t, server, seats.gameID, seats.tokens[1], strconv.Itoa(seats.version-1))
+
+	// Only the stream that missed the current version is sent it
+	if version := nextEventID(t, behind); version != seats.version {
+		t.Errorf("stream one version behind resumed at %d, want %d", version, seats.version)
+	}
+	call(t, router, "POST", "/api/games/"+seats.gameID+"/withdraw", seats.currentToken, nil, http.StatusOK, nil)
+	for name, stream := range map[string]*bufio.Reader{"up-to-date": current, "caught-up": behind} {
+		if version := nextEventID(t, stream); version != seats.version+1 {
+			t.Errorf("%s stream got version %d, want %d", name, version, seats.version+1)
+		}
+	}
+}
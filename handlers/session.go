@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dfturn/alns/models"
+	"github.com/dfturn/alns/service"
+)
+
+var (
+	errMissingSession  = errors.New("missing session token")
+	errInvalidSession  = errors.New("invalid session token")
+	errSessionReplaced = errors.New("session has been replaced by a newer one")
+	errSpectating      = errors.New("spectators cannot act in the game")
+	errWrongGame       = errors.New("session is not for this game")
+	errWrongRoom       = errors.New("session is not for this room")
+)
+
+// sessionClaims identify the player a session token was issued to
+type sessionClaims struct {
+	PlayerID  string `json:"playerId"`
+	RoomID    string `json:"roomId"`
+	SessionID string `json:"sid"`
+	IssuedAt  int64  `json:"iat"`
+}
+
+// issueToken signs a session token for a player's seat, or a spectator,
+// in a room. Tokens have the form base64(claims) "." base64(HMAC-SHA256(claims)).
+func (h *Handler) issueToken(room *models.Room, playerID string) (string, error) {
+	seat, ok := room.Seats[playerID]
+	if !ok {
+		seat = room.Spectators[playerID]
+	}
+
+	payload, err := json.Marshal(sessionClaims{
+		PlayerID:  playerID,
+		RoomID:    room.ID,
+		SessionID: seat.SessionID,
+		IssuedAt:  time.Now().Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + h.sign(encoded), nil
+}
+
+// sign returns the encoded HMAC of a token payload
+func (h *Handler) sign(payload string) string {
+	mac := hmac.New(sha256.New, h.sessionSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseToken verifies a session token and returns its claims
+func (h *Handler) parseToken(token string) (sessionClaims, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(h.sign(payload))) {
+		return sessionClaims{}, errInvalidSession
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return sessionClaims{}, errInvalidSession
+	}
+
+	var claims sessionClaims
+	if err := json.Unmarshal(data, &claims); err != nil || claims.PlayerID == "" {
+		return sessionClaims{}, errInvalidSession
+	}
+	return claims, nil
+}
+
+// sessionToken reads the bearer token from the Authorization header
+func sessionToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// streamToken reads the session token of an events stream. The token
+// query parameter is accepted too, since EventSource cannot set headers;
+// no other route takes it, so tokens stay out of most URLs and logs.
+func streamToken(r *http.Request) string {
+	if r.Header.Get("Authorization") != "" {
+		return sessionToken(r)
+	}
+	return r.URL.Query().Get("token")
+}
+
+// session verifies a session token and checks that it is still the
+// current session of its seat. On failure it writes the error response
+// and returns false.
+func (h *Handler) session(w http.ResponseWriter, token string) (sessionClaims, bool) {
+	if token == "" {
+		writeError(w, errMissingSession, http.StatusUnauthorized)
+		return sessionClaims{}, false
+	}
+
+	claims, err := h.parseToken(token)
+	if err != nil {
+		writeError(w, err, http.StatusUnauthorized)
+		return sessionClaims{}, false
+	}
+
+	if !h.sessionCurrent(claims) {
+		writeError(w, errSessionReplaced, http.StatusUnauthorized)
+		return sessionClaims{}, false
+	}
+	return claims, true
+}
+
+// sessionCurrent reports whether a session is still the one its seat was
+// last issued. Reclaiming a seat replaces it.
+func (h *Handler) sessionCurrent(claims sessionClaims) bool {
+	current, err := h.gameService.CheckSession(claims.RoomID, claims.PlayerID, claims.SessionID)
+	return err == nil && current
+}
+
+// authorize resolves the player making a request to a game from their
+// session token. Spectators are turned away. On failure it writes the
+// error response and returns false.
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, gameID string) (string, bool) {
+	playerID, spectating, ok := h.authorizeViewer(w, sessionToken(r), gameID)
+	if !ok {
+		return "", false
+	}
+
+	if spectating {
+		writeError(w, errSpectating, http.StatusForbidden)
+		return "", false
+	}
+
+	return playerID, true
+}
+
+// authorizeViewer resolves who is looking at a game from their session
+// token: one of its players, or a spectator of its room. On failure it
+// writes the error response and returns false.
+func (h *Handler) authorizeViewer(w http.ResponseWriter, token, gameID string) (string, bool, bool) {
+	claims, ok := h.session(w, token)
+	if !ok {
+		return "", false, false
+	}
+
+	game, err := h.gameService.GetGame(gameID)
+	if err != nil {
+		writeError(w, err, http.StatusNotFound)
+		return "", false, false
+	}
+
+	if game.RoomID != claims.RoomID {
+		writeError(w, errWrongGame, http.StatusForbidden)
+		return "", false, false
+	}
+
+	room, err := h.gameService.GetRoom(claims.RoomID)
+	if err != nil {
+		writeError(w, err, http.StatusNotFound)
+		return "", false, false
+	}
+
+	return claims.PlayerID, service.IsSpectator(room, claims.PlayerID), true
+}
+
+// authorizeRoom resolves the player making a request to a room from their
+// session token. On failure it writes the error response and returns false.
+func (h *Handler) authorizeRoom(w http.ResponseWriter, r *http.Request, roomID string) (string, bool) {
+	claims, ok := h.session(w, sessionToken(r))
+	if !ok {
+		return "", false
+	}
+
+	if claims.RoomID != roomID {
+		writeError(w, errWrongRoom, http.StatusForbidden)
+		return "", false
+	}
+
+	return claims.PlayerID, true
+}
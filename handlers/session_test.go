@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+	"github.com/dfturn/alns/service"
+	"github.com/gorilla/mux"
+)
+
+// newTestRouter serves the routes the handler tests use
+func newTestRouter() http.Handler {
+	h := NewHandler(service.NewGameService(service.NewMemoryStore()), []byte("test secret"))
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/rooms", h.CreateRoom).Methods("POST")
+	api.HandleFunc("/rooms/{id}/join", h.JoinRoom).Methods("POST")
+	api.HandleFunc("/rooms/{id}/reclaim", h.ReclaimSeat).Methods("POST")
+	api.HandleFunc("/games/{id}", h.GetGame).Methods("GET")
+	api.HandleFunc("/games/{id}/events", h.GameEvents).Methods("GET")
+	api.HandleFunc("/games/{id}/withdraw", h.Withdraw).Methods("POST")
+	return router
+}
+
+// testSeats are the sessions of both players of a game started over the API
+type testSeats struct {
+	roomID       string
+	gameID       string
+	version      int
+	tokens       [2]string
+	playerIDs    [2]string
+	currentToken string // Token of the player whose turn it is
+	recoveryCode string // Of the first player
+}
+
+func startTestGame(t *testing.T, router http.Handler) testSeats {
+	t.Helper()
+	var created CreateRoomResponse
+	call(t, router, "POST", "/api/rooms", "", CreateRoomRequest{PlayerName: "Alice"}, http.StatusOK, &created)
+	var joined JoinRoomResponse
+	call(t, router, "POST", "/api/rooms/"+created.Room.ID+"/join", "", JoinRoomRequest{PlayerName: "Bob"}, http.StatusOK, &joined)
+
+	seats := testSeats{
+		roomID:       created.Room.ID,
+		gameID:       joined.Game.ID,
+		version:      joined.Game.Version,
+		tokens:       [2]string{created.Token, joined.Token},
+		playerIDs:    [2]string{created.PlayerID, joined.PlayerID},
+		recoveryCode: created.RecoveryCode,
+	}
+	seats.currentToken = seats.tokens[0]
+	if joined.Game.CurrentPlayerID == joined.PlayerID {
+		seats.currentToken = seats.tokens[1]
+	}
+	return seats
+}
+
+// request sends a request to the router, with token as its bearer token
+func request(router http.Handler, method, path, token string, body any, header http.Header) *httptest.ResponseRecorder {
+	var reader bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&reader).Encode(body)
+	}
+	r := httptest.NewRequest(method, path, &reader)
+	for key, values := range header {
+		r.Header[key] = values
+	}
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+// call sends a request, checks its status and decodes the response into out
+func call(t *testing.T, router http.Handler, method, path, token string, body any, status int, out any) {
+	t.Helper()
+	w := request(router, method, path, token, body, nil)
+	if w.Code != status {
+		t.Fatalf("%s %s = %d %s, want %d", method, path, w.Code, w.Body, status)
+	}
+	if out != nil {
+		if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkError checks the status and error code of a response
+func checkError(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	var resp ErrorResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != status || resp.Code != code {
+		t.Errorf("response = %d %q, want %d %q", w.Code, resp.Code, status, code)
+	}
+}
+
+func TestRequestsWithoutSessionAreRejected(t *testing.T) {
+	router := newTestRouter()
+	seats := startTestGame(t, router)
+
+	w := request(router, "GET", "/api/games/"+seats.gameID, "", nil, nil)
+	checkError(t, w, http.StatusUnauthorized, "missing_session")
+}
+
+func TestTamperedTokensAreRejected(t *testing.T) {
+	router := newTestRouter()
+	seats := startTestGame(t, router)
+
+	// Claim the other seat while keeping the first seat's signature
+	payload, signature, _ := strings.Cut(seats.tokens[0], ".")
+	other, _, _ := strings.Cut(seats.tokens[1], ".")
+	tampered := []string{
+		payload + "." + strings.Repeat("A", len(signature)),
+		other + "." + signature,
+		payload,
+	}
+	for _, token := range tampered {
+		w := request(router, "GET", "/api/games/"+seats.gameID, token, nil, nil)
+		checkError(t, w, http.StatusUnauthorized, "invalid_session")
+	}
+}
+
+func TestTokensOnlyWorkForTheirOwnGame(t *testing.T) {
+	router := newTestRouter()
+	first := startTestGame(t, router)
+	second := startTestGame(t, router)
+
+	w := request(router, "GET", "/api/games/"+second.gameID, first.tokens[0], nil, nil)
+	checkError(t, w, http.StatusForbidden, "wrong_game")
+}
+
+func TestReclaimReplacesSession(t *testing.T) {
+	router := newTestRouter()
+	seats := startTestGame(t, router)
+
+	var reclaimed ReclaimSeatResponse
+	call(t, router, "POST", "/api/rooms/"+seats.roomID+"/reclaim", "", ReclaimSeatRequest{RecoveryCode: seats.recoveryCode}, http.StatusOK, &reclaimed)
+
+	w := request(router, "GET", "/api/games/"+seats.gameID, seats.tokens[0], nil, nil)
+	checkError(t, w, http.StatusUnauthorized, "session_replaced")
+
+	var view models.GameView
+	call(t, router, "GET", "/api/games/"+seats.gameID, reclaimed.Token, nil, http.StatusOK, &view)
+	if view.ViewerID != seats.playerIDs[0] {
+		t.Errorf("reclaimed session views the game as %q, want %q", view.ViewerID, seats.playerIDs[0])
+	}
+}
+
+func TestQueryTokenOnlyWorksForEvents(t *testing.T) {
+	router := newTestRouter()
+	seats := startTestGame(t, router)
+	query := "?token=" + seats.tokens[0]
+
+	w := request(router, "GET", "/api/games/"+seats.gameID+query, "", nil, nil)
+	checkError(t, w, http.StatusUnauthorized, "missing_session")
+	w = request(router, "POST", "/api/games/"+seats.gameID+"/withdraw"+query, "", nil, nil)
+	checkError(t, w, http.StatusUnauthorized, "missing_session")
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/games/"+seats.gameID+"/events"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("events with a query token = %d %s, want an event stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestReclaimRejectsWrongRecoveryCode(t *testing.T) {
+	router := newTestRouter()
+	seats := startTestGame(t, router)
+
+	w := request(router, "POST", "/api/rooms/"+seats.roomID+"/reclaim", "", ReclaimSeatRequest{RecoveryCode: "AAAA-AAAA"}, nil)
+	checkError(t, w, http.StatusForbidden, "invalid_recovery_code")
+
+	// The seat's session is untouched
+	call(t, router, "GET", "/api/games/"+seats.gameID, seats.tokens[0], nil, http.StatusOK, nil)
+}
@@ -1,38 +1,85 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/dfturn/alns/bot"
 	"github.com/dfturn/alns/handlers"
 	"github.com/dfturn/alns/service"
 	"github.com/gorilla/mux"
 )
 
 func main() {
+	// Initialize storage; set STORE_PATH to a file to keep games across restarts
+	var store service.Store = service.NewMemoryStore()
+	var fileStore *service.FileStore
+	if path := os.Getenv("STORE_PATH"); path != "" {
+		var err error
+		fileStore, err = service.NewFileStore(path)
+		if err != nil {
+			log.Fatalf("Failed to open store %s: %v", path, err)
+		}
+		store = fileStore
+		log.Printf("Persisting games to %s", path)
+	}
+
 	// Initialize service
-	gameService := service.NewGameService()
-	handler := handlers.NewHandler(gameService)
+	gameService := service.NewGameService(store)
+	handler := handlers.NewHandler(gameService, sessionSecret())
+
+	// Pick up bot games that were in progress before a restart
+	if err := bot.Resume(gameService); err != nil {
+		log.Printf("Failed to resume bots: %v", err)
+	}
+
+	// Restart the clocks of timed games
+	if err := gameService.ResumeClocks(); err != nil {
+		log.Printf("Failed to resume clocks: %v", err)
+	}
+
+	// Clear out rooms and games nobody is using any more
+	stopJanitor := gameService.StartJanitor(roomExpiry(), janitorInterval)
 
 	// Setup router
 	r := mux.NewRouter()
 
 	// API routes
 	api := r.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/lobby", handler.ListLobby).Methods("GET")
+	api.HandleFunc("/matchmaking", handler.QuickMatch).Methods("POST")
+	api.HandleFunc("/matchmaking/{id}", handler.GetMatch).Methods("GET")
+	api.HandleFunc("/matchmaking/{id}", handler.CancelMatch).Methods("DELETE")
 	api.HandleFunc("/rooms", handler.CreateRoom).Methods("POST")
 	api.HandleFunc("/rooms/{id}", handler.GetRoom).Methods("GET")
 	api.HandleFunc("/rooms/{id}/join", handler.JoinRoom).Methods("POST")
+	api.HandleFunc("/rooms/{id}/reclaim", handler.ReclaimSeat).Methods("POST")
+	api.HandleFunc("/rooms/{id}/spectate", handler.Spectate).Methods("POST")
+	api.HandleFunc("/rooms/{id}/leave", handler.LeaveRoom).Methods("POST")
+	api.HandleFunc("/rooms/{id}/close", handler.CloseRoom).Methods("POST")
 	api.HandleFunc("/games/{id}", handler.GetGame).Methods("GET")
+	api.HandleFunc("/games/{id}/events", handler.GameEvents).Methods("GET")
+	api.HandleFunc("/games/{id}/history", handler.GetHistory).Methods("GET")
+	api.HandleFunc("/games/{id}/replay/{index}", handler.ReplayGame).Methods("GET")
 	api.HandleFunc("/games/{id}/play-card", handler.PlayCard).Methods("POST")
 	api.HandleFunc("/games/{id}/end-turn", handler.EndTurn).Methods("POST")
-	api.HandleFunc("/games/{id}/draw-card", handler.DrawCard).Methods("POST")
-	api.HandleFunc("/games/{id}/manipulate-card", handler.ManipulateCard).Methods("POST")
-	api.HandleFunc("/games/{id}/destroy-card", handler.DestroyCard).Methods("POST")
+	api.HandleFunc("/games/{id}/resolve-ability", handler.ResolveAbility).Methods("POST")
 	api.HandleFunc("/games/{id}/withdraw", handler.Withdraw).Methods("POST")
 	api.HandleFunc("/games/{id}/update-scores", handler.UpdateScores).Methods("POST")
+	api.HandleFunc("/games/{id}/accept-scores", handler.AcceptScores).Methods("POST")
 	api.HandleFunc("/games/{id}/next-battle", handler.StartNextBattle).Methods("POST")
 	api.HandleFunc("/games/{id}/next-game", handler.StartNextGame).Methods("POST")
+	api.HandleFunc("/games/{id}/draw-card", handler.DrawCard).Methods("POST")
+	api.HandleFunc("/games/{id}/manipulate-card", handler.ManipulateCard).Methods("POST")
+	api.HandleFunc("/games/{id}/destroy-card", handler.DestroyCard).Methods("POST")
 
 	// Serve static files from frontend build
 	staticDir := "./frontend/dist"
@@ -49,8 +96,84 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := http.ListenAndServe(":"+port, corsRouter); err != nil {
-		log.Fatal(err)
+	// Event streams never finish on their own, so requests get a context
+	// that is cancelled when the server shuts down
+	requests, endRequests := context.WithCancel(context.Background())
+	server := &http.Server{
+		Addr:        ":" + port,
+		Handler:     corsRouter,
+		BaseContext: func(net.Listener) context.Context { return requests },
+	}
+
+	go func() {
+		log.Printf("Server starting on port %s", port)
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// Shut down cleanly on SIGINT or SIGTERM
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-interrupted.Done()
+
+	log.Printf("Shutting down")
+	endRequests()
+	shutdown, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdown); err != nil {
+		log.Printf("Failed to finish requests: %v", err)
+	}
+	stopJanitor()
+	if fileStore != nil {
+		if err := fileStore.Close(); err != nil {
+			log.Printf("Failed to close store: %v", err)
+		}
+	}
+}
+
+// shutdownTimeout is how long requests in flight get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// janitorInterval is how often expired rooms are swept out
+const janitorInterval = time.Minute
+
+// roomExpiry reads how long to keep inactive rooms and games from
+// WAITING_ROOM_TTL, FINISHED_GAME_TTL and IDLE_GAME_TTL, e.g. "30m" or
+// "48h". Unset variables keep the service defaults.
+func roomExpiry() service.Expiry {
+	return service.Expiry{
+		WaitingRoom:  envDuration("WAITING_ROOM_TTL"),
+		FinishedGame: envDuration("FINISHED_GAME_TTL"),
+		IdleGame:     envDuration("IDLE_GAME_TTL"),
+	}
+}
+
+// envDuration parses a duration from an environment variable, returning
+// zero when it is unset
+func envDuration(name string) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("Invalid %s %q: must be a positive duration", name, value)
+	}
+	return d
+}
+
+// sessionSecret returns the key used to sign session tokens. Without
+// SESSION_SECRET a random key is used and sessions end on restart.
+func sessionSecret() []byte {
+	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate session secret: %v", err)
 	}
+	log.Printf("SESSION_SECRET not set; sessions will not survive a restart")
+	return secret
 }
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // TheaterType represents the three theaters in the game
 type TheaterType string
 
@@ -9,12 +11,33 @@ const (
 	Sea  TheaterType = "sea"
 )
 
+// Ability represents the tactical ability printed on a card
+type Ability string
+
+const (
+	AbilityNone        Ability = ""
+	AbilitySupport     Ability = "support"
+	AbilityAirDrop     Ability = "air_drop"
+	AbilityManeuver    Ability = "maneuver"
+	AbilityAerodrome   Ability = "aerodrome"
+	AbilityContainment Ability = "containment"
+	AbilityReinforce   Ability = "reinforce"
+	AbilityAmbush      Ability = "ambush"
+	AbilityCoverFire   Ability = "cover_fire"
+	AbilityDisrupt     Ability = "disrupt"
+	AbilityTransport   Ability = "transport"
+	AbilityEscalation  Ability = "escalation"
+	AbilityRedeploy    Ability = "redeploy"
+	AbilityBlockade    Ability = "blockade"
+)
+
 // Card represents a single game card
 type Card struct {
-	ID       int         `json:"id"`       // 1-18
-	Theater  TheaterType `json:"theater"`  // Air, Land, or Sea
-	Strength int         `json:"strength"` // 0-6
-	Name     string      `json:"name"`     // Card name for reference
+	ID       int         `json:"id"`                // 1-18
+	Theater  TheaterType `json:"theater"`           // Air, Land, or Sea
+	Strength int         `json:"strength"`          // 0-6
+	Name     string      `json:"name"`              // Card name for reference
+	Ability  Ability     `json:"ability,omitempty"` // Empty for cards without an ability
 }
 
 // PlayedCard represents a card that has been played to a theater
@@ -30,6 +53,30 @@ type Theater struct {
 	Cards []PlayedCard `json:"cards"`
 }
 
+// PendingAbility is an instant ability waiting for a player's choice.
+// PlayerID is the player who must make the next choice, which is not
+// always the owner of the card (e.g. the first step of Disrupt).
+type PendingAbility struct {
+	Ability      Ability     `json:"ability"`
+	PlayerID     string      `json:"playerId"`
+	OwnerID      string      `json:"ownerId"`
+	SourceCardID int         `json:"sourceCardId"`
+	Theater      TheaterType `json:"theater"`
+	Step         int         `json:"step,omitempty"`
+	Optional     bool        `json:"optional"`
+	RevealedCard *Card       `json:"revealedCard,omitempty"` // Reinforce: top card of the deck
+}
+
+// AbilityChoice is a player's answer to the pending ability.
+// Which fields are required depends on the ability being resolved.
+type AbilityChoice struct {
+	Skip      bool        `json:"skip,omitempty"`      // Decline an optional ability
+	CardID    int         `json:"cardId,omitempty"`    // Target card on the board
+	Theater   TheaterType `json:"theater,omitempty"`   // Theater of a face-down target whose ID is hidden
+	OwnerID   string      `json:"ownerId,omitempty"`   // Owner of a face-down target whose ID is hidden
+	ToTheater TheaterType `json:"toTheater,omitempty"` // Destination theater (Transport, Reinforce)
+}
+
 // Player represents a player in the game
 type Player struct {
 	ID    string `json:"id"`
@@ -40,14 +87,16 @@ type Player struct {
 
 // TheaterScore represents the strength totals for a theater
 type TheaterScore struct {
-	Player1Total int `json:"player1Total"`
-	Player2Total int `json:"player2Total"`
+	Player1Total int    `json:"player1Total"`
+	Player2Total int    `json:"player2Total"`
+	ControllerID string `json:"controllerId,omitempty"` // Set once the battle is decided
 }
 
 // GameState represents the current state of a game
 type GameState struct {
 	ID               string                        `json:"id"`
 	RoomID           string                        `json:"roomId"`
+	Version          int                           `json:"version"` // Incremented on every change
 	Player1          Player                        `json:"player1"`
 	Player2          Player                        `json:"player2"`
 	Deck             []Card                        `json:"deck"` // Remaining undealt cards
@@ -60,6 +109,171 @@ type GameState struct {
 	FirstPlayerID    string                        `json:"firstPlayerId"` // Who went first this battle
 	WithdrewPlayerID string                        `json:"withdrewPlayerId,omitempty"`
 	TheaterScores    map[TheaterType]*TheaterScore `json:"theaterScores,omitempty"`
+	BattleWinnerID   string                        `json:"battleWinnerId,omitempty"`
+
+	// Score confirmation. TheaterScores holds the server's totals until the
+	// battle is decided; players submit totals for both sides and any theater
+	// where a submission differs from the server's is disputed.
+	ScoreSubmissions map[string]map[TheaterType]TheaterScore `json:"scoreSubmissions,omitempty"`
+	DisputedTheaters []TheaterType                           `json:"disputedTheaters,omitempty"`
+
+	// Ability engine state
+	PendingAbilities  []PendingAbility `json:"pendingAbilities,omitempty"` // Stack, last entry resolves first
+	AirDropPlayerID   string           `json:"airDropPlayerId,omitempty"`
+	AirDropReady      bool             `json:"airDropReady,omitempty"` // Air Drop can be used this turn
+	ExtraTurnPlayerID string           `json:"extraTurnPlayerId,omitempty"`
+
+	Mode GameMode `json:"mode"`
+
+	// Deals are shuffled from Seed; Deals counts the hands dealt so far
+	Seed  int64 `json:"seed"`
+	Deals int   `json:"deals"`
+
+	TimeControl *TimeControl `json:"timeControl,omitempty"`
+	Clock       *Clock       `json:"clock,omitempty"`
+
+	WinnerID          string `json:"winnerId,omitempty"`          // Set when the game is over
+	ForfeitedPlayerID string `json:"forfeitedPlayerId,omitempty"` // Player who ran out of time
+	LeftPlayerID      string `json:"leftPlayerId,omitempty"`      // Player who left the room
+
+	// Battles decided so far this game, in order. Scores are the sums of
+	// their VP.
+	BattleResults []BattleResult `json:"battleResults,omitempty"`
+
+	Series *Series `json:"series,omitempty"`
+
+	// Decided series played earlier in the room, oldest first
+	SeriesHistory []Series `json:"seriesHistory,omitempty"`
+}
+
+// Series is a best-of-N run of games between the two players of a room.
+// The first player to win a majority of its games wins the series.
+type Series struct {
+	BestOf   int            `json:"bestOf"`
+	Games    []GameResult   `json:"games"`              // Completed games, in order
+	Wins     map[string]int `json:"wins"`               // Games won, by player ID
+	WinnerID string         `json:"winnerId,omitempty"` // Set once the series is decided
+}
+
+// GameResult records how a game of a series ended
+type GameResult struct {
+	GameNumber        int            `json:"gameNumber"`
+	WinnerID          string         `json:"winnerId"`
+	Scores            map[string]int `json:"scores"` // Final VP, by player ID
+	Battles           int            `json:"battles"`
+	ForfeitedPlayerID string         `json:"forfeitedPlayerId,omitempty"`
+	LeftPlayerID      string         `json:"leftPlayerId,omitempty"`
+}
+
+// BattleResult records how a battle was decided and the board it was
+// decided on
+type BattleResult struct {
+	BattleNumber     int    `json:"battleNumber"`
+	FirstPlayerID    string `json:"firstPlayerId"`
+	WinnerID         string `json:"winnerId"`
+	VP               int    `json:"vp"`
+	WithdrewPlayerID string `json:"withdrewPlayerId,omitempty"` // Set when the battle ended in a withdrawal
+	CardsRemaining   int    `json:"cardsRemaining"`             // Cards the withdrawing player had left
+
+	// Final totals of both players in every theater. Controllers are only
+	// set for battles that were scored.
+	TheaterScores map[TheaterType]TheaterScore `json:"theaterScores"`
+	Board         []Theater                    `json:"board"` // Final board, in theater order
+}
+
+// PlayerView is a player as seen by one of the participants. Hand is
+// empty unless the viewer is this player.
+type PlayerView struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Hand      []Card `json:"hand"`
+	HandCount int    `json:"handCount"`
+	Score     int    `json:"score"`
+}
+
+// GameView is a game as seen by one player. The opponent's hand, the deck
+// and the identity of face-down cards the viewer does not own are hidden.
+type GameView struct {
+	ID                string                                  `json:"id"`
+	RoomID            string                                  `json:"roomId"`
+	Version           int                                     `json:"version"`
+	ViewerID          string                                  `json:"viewerId,omitempty"`
+	Player1           PlayerView                              `json:"player1"`
+	Player2           PlayerView                              `json:"player2"`
+	DeckCount         int                                     `json:"deckCount"`
+	TrashCount        int                                     `json:"trashCount"`
+	TheaterOrder      []TheaterType                           `json:"theaterOrder"`
+	Theaters          map[TheaterType]*Theater                `json:"theaters"`
+	CurrentPlayerID   string                                  `json:"currentPlayerId"`
+	Phase             GamePhase                               `json:"phase"`
+	BattleNumber      int                                     `json:"battleNumber"`
+	FirstPlayerID     string                                  `json:"firstPlayerId"`
+	WithdrewPlayerID  string                                  `json:"withdrewPlayerId,omitempty"`
+	TheaterScores     map[TheaterType]*TheaterScore           `json:"theaterScores,omitempty"`
+	ScoreSubmissions  map[string]map[TheaterType]TheaterScore `json:"scoreSubmissions,omitempty"`
+	DisputedTheaters  []TheaterType                           `json:"disputedTheaters,omitempty"`
+	BattleWinnerID    string                                  `json:"battleWinnerId,omitempty"`
+	PendingAbilities  []PendingAbility                        `json:"pendingAbilities,omitempty"`
+	AirDropPlayerID   string                                  `json:"airDropPlayerId,omitempty"`
+	AirDropReady      bool                                    `json:"airDropReady,omitempty"`
+	ExtraTurnPlayerID string                                  `json:"extraTurnPlayerId,omitempty"`
+	Mode              GameMode                                `json:"mode"`
+	TimeControl       *TimeControl                            `json:"timeControl,omitempty"`
+	Clock             *Clock                                  `json:"clock,omitempty"` // Remaining time as of the view
+	WinnerID          string                                  `json:"winnerId,omitempty"`
+	ForfeitedPlayerID string                                  `json:"forfeitedPlayerId,omitempty"`
+	LeftPlayerID      string                                  `json:"leftPlayerId,omitempty"`
+	BattleResults     []BattleResult                          `json:"battleResults,omitempty"`
+	Series            *Series                                 `json:"series,omitempty"`
+	SeriesHistory     []Series                                `json:"seriesHistory,omitempty"`
+	Spectating        bool                                    `json:"spectating,omitempty"` // Read-only view for a spectator
+}
+
+// ActionType identifies an action accepted by the game service
+type ActionType string
+
+const (
+	ActionStartGame      ActionType = "start_game"
+	ActionPlayCard       ActionType = "play_card"
+	ActionResolveAbility ActionType = "resolve_ability"
+	ActionEndTurn        ActionType = "end_turn"
+	ActionWithdraw       ActionType = "withdraw"
+	ActionUpdateScores   ActionType = "update_scores"
+	ActionAcceptScores   ActionType = "accept_scores"
+	ActionNextBattle     ActionType = "next_battle"
+	ActionNextGame       ActionType = "next_game"
+	ActionTimeout        ActionType = "timeout" // Issued by the server when a clock runs out
+	ActionLeave          ActionType = "leave"
+
+	// Sandbox mode only
+	ActionDrawCard       ActionType = "draw_card"
+	ActionManipulateCard ActionType = "manipulate_card"
+	ActionDestroyCard    ActionType = "destroy_card"
+)
+
+// Action is a single change to a game. Which fields are set depends on Type.
+type Action struct {
+	Type         ActionType                   `json:"type"`
+	PlayerID     string                       `json:"playerId,omitempty"`
+	CardID       int                          `json:"cardId,omitempty"`
+	Theater      TheaterType                  `json:"theater,omitempty"`
+	FaceUp       bool                         `json:"faceUp,omitempty"`
+	Choice       *AbilityChoice               `json:"choice,omitempty"`
+	Totals       map[TheaterType]TheaterScore `json:"totals,omitempty"`       // Both players' totals as submitted by PlayerID
+	Manipulation string                       `json:"manipulation,omitempty"` // "flip", "destroy" or "return"
+	Deal         []Card                       `json:"deal,omitempty"`         // Shuffled deck for a new battle or game
+	At           time.Time                    `json:"at,omitempty"`           // When the server accepted the action
+}
+
+// GameEvent is an entry in a game's move log. The first event of every
+// game is ActionStartGame and carries the initial state; replaying the
+// following actions on it reproduces the game.
+type GameEvent struct {
+	Index     int        `json:"index"`
+	Version   int        `json:"version"` // Game version after the action
+	Timestamp time.Time  `json:"timestamp"`
+	Action    Action     `json:"action"`
+	Initial   *GameState `json:"initial,omitempty"`
 }
 
 // GamePhase represents the current phase of the game
@@ -72,13 +286,127 @@ const (
 	PhaseGameOver          GamePhase = "game_over"
 )
 
+// GameMode selects how strictly the rules are enforced
+type GameMode string
+
+const (
+	// ModeStrict allows exactly one Deploy, Improvise or Withdraw per turn,
+	// after which the turn passes automatically
+	ModeStrict GameMode = "strict"
+	// ModeSandbox lets players act freely and end their turn themselves,
+	// with manual draw, flip, destroy and return actions
+	ModeSandbox GameMode = "sandbox"
+)
+
+// OpponentType says who takes the second seat of a room
+type OpponentType string
+
+const (
+	OpponentHuman OpponentType = "human"
+	OpponentBot   OpponentType = "bot"
+)
+
+// ExpiryAction is what happens to a player who runs out of time
+type ExpiryAction string
+
+const (
+	// ExpiryWithdraw withdraws the player from the current battle
+	ExpiryWithdraw ExpiryAction = "withdraw"
+	// ExpiryForfeit ends the game in the opponent's favor
+	ExpiryForfeit ExpiryAction = "forfeit"
+)
+
+// TimeControl limits how long players may think. With PerMoveSeconds set,
+// every decision must be made within that time. Otherwise each player has
+// a bank of BankSeconds for the whole game that grows by IncrementSeconds
+// after each of their actions.
+type TimeControl struct {
+	PerMoveSeconds   int          `json:"perMoveSeconds,omitempty"`
+	BankSeconds      int          `json:"bankSeconds,omitempty"`
+	IncrementSeconds int          `json:"incrementSeconds,omitempty"`
+	OnExpiry         ExpiryAction `json:"onExpiry,omitempty"` // Defaults to ExpiryWithdraw
+}
+
+// Clock tracks the time players have left. Only the player who has to act
+// next has their time running.
+type Clock struct {
+	RemainingMs     map[string]int64 `json:"remainingMs"` // By player ID
+	RunningPlayerID string           `json:"runningPlayerId,omitempty"`
+	RunningSince    time.Time        `json:"runningSince,omitempty"`
+}
+
+// RoomOptions are the rule options chosen when a room is created
+type RoomOptions struct {
+	Mode        GameMode     `json:"mode,omitempty"`        // Defaults to ModeStrict
+	Opponent    OpponentType `json:"opponent,omitempty"`    // Defaults to OpponentHuman
+	Level       int          `json:"level,omitempty"`       // Bot difficulty
+	Seed        *int64       `json:"seed,omitempty"`        // Chosen at random when unset
+	TimeControl *TimeControl `json:"timeControl,omitempty"` // No time limit when unset
+	// AllowSpectators lets anyone with the room code watch the game.
+	// Spectators see it SpectatorDelay moves behind while it is in progress.
+	AllowSpectators bool `json:"allowSpectators,omitempty"`
+	SpectatorDelay  int  `json:"spectatorDelay,omitempty"`
+	// Private rooms are left out of the lobby and can only be joined by code
+	Private bool `json:"private,omitempty"`
+	BestOf  int  `json:"bestOf,omitempty"` // Odd number of games in the series; defaults to 1
+}
+
+// LobbyRoom is an open room as listed in the lobby
+type LobbyRoom struct {
+	ID              string       `json:"id"`
+	HostName        string       `json:"hostName"`
+	Mode            GameMode     `json:"mode"`
+	TimeControl     *TimeControl `json:"timeControl,omitempty"`
+	BestOf          int          `json:"bestOf"`
+	AllowSpectators bool         `json:"allowSpectators,omitempty"`
+	SpectatorDelay  int          `json:"spectatorDelay,omitempty"`
+	SpectatorCount  int          `json:"spectatorCount"`
+	WaitingSince    time.Time    `json:"waitingSince"`
+}
+
+// MatchStatus is the state of a quick-match ticket
+type MatchStatus string
+
+const (
+	MatchWaiting MatchStatus = "waiting"
+	MatchFound   MatchStatus = "matched"
+)
+
+// MatchTicket is a player's place in the quick-match queue. Once matched
+// it names the room and seat the player was given.
+type MatchTicket struct {
+	ID         string      `json:"id"`
+	PlayerName string      `json:"playerName"`
+	Options    RoomOptions `json:"options"`
+	Status     MatchStatus `json:"status"`
+	RoomID     string      `json:"roomId,omitempty"`
+	PlayerID   string      `json:"playerId,omitempty"`
+	LastSeen   time.Time   `json:"lastSeen"` // Last time the player checked the ticket
+}
+
 // Room represents a game room that players can join
 type Room struct {
-	ID      string     `json:"id"`
-	Player1 *Player    `json:"player1,omitempty"`
-	Player2 *Player    `json:"player2,omitempty"`
-	GameID  string     `json:"gameId,omitempty"`
-	Status  RoomStatus `json:"status"`
+	ID           string      `json:"id"`
+	Player1      *Player     `json:"player1,omitempty"`
+	Player2      *Player     `json:"player2,omitempty"`
+	GameID       string      `json:"gameId,omitempty"`
+	Status       RoomStatus  `json:"status"`
+	Options      RoomOptions `json:"options"`
+	LeftPlayerID string      `json:"leftPlayerId,omitempty"` // Player who left or closed the room
+	UpdatedAt    time.Time   `json:"updatedAt"`              // Last change to the room itself
+	// Seats hold each player's credentials by player ID. They are secret
+	// and left out of room views.
+	Seats map[string]Seat `json:"seats,omitempty"`
+	// Spectators hold the sessions of spectators by spectator ID, and are
+	// left out of room views too, which only report SpectatorCount
+	Spectators     map[string]Seat `json:"spectators,omitempty"`
+	SpectatorCount int             `json:"spectatorCount"`
+}
+
+// Seat holds the credentials of a player's seat in a room
+type Seat struct {
+	SessionID    string `json:"sessionId"`    // Only tokens for this session are accepted
+	RecoveryCode string `json:"recoveryCode"` // Reclaims the seat under a new session
 }
 
 // RoomStatus represents the status of a room
@@ -88,33 +416,37 @@ const (
 	RoomStatusWaiting RoomStatus = "waiting"
 	RoomStatusFull    RoomStatus = "full"
 	RoomStatusPlaying RoomStatus = "playing"
+	// RoomStatusClosed rooms were closed before or after their game
+	RoomStatusClosed RoomStatus = "closed"
+	// RoomStatusAbandoned rooms were left while their game was in progress
+	RoomStatusAbandoned RoomStatus = "abandoned"
 )
 
 // AllCards returns all 18 cards in the game
 func AllCards() []Card {
 	return []Card{
 		// Air Cards (1-6)
-		{ID: 1, Theater: Air, Strength: 1, Name: "Air Drop"},
-		{ID: 2, Theater: Air, Strength: 2, Name: "Air Superiority"},
-		{ID: 3, Theater: Air, Strength: 3, Name: "Aerodrome"},
-		{ID: 4, Theater: Air, Strength: 4, Name: "Maneuver"},
-		{ID: 5, Theater: Air, Strength: 5, Name: "Transport"},
+		{ID: 1, Theater: Air, Strength: 1, Name: "Support", Ability: AbilitySupport},
+		{ID: 2, Theater: Air, Strength: 2, Name: "Air Drop", Ability: AbilityAirDrop},
+		{ID: 3, Theater: Air, Strength: 3, Name: "Maneuver", Ability: AbilityManeuver},
+		{ID: 4, Theater: Air, Strength: 4, Name: "Aerodrome", Ability: AbilityAerodrome},
+		{ID: 5, Theater: Air, Strength: 5, Name: "Containment", Ability: AbilityContainment},
 		{ID: 6, Theater: Air, Strength: 6, Name: "Heavy Bombers"},
 
 		// Land Cards (7-12)
-		{ID: 7, Theater: Land, Strength: 1, Name: "Ambush"},
-		{ID: 8, Theater: Land, Strength: 2, Name: "Reconnaissance"},
-		{ID: 9, Theater: Land, Strength: 3, Name: "Support"},
-		{ID: 10, Theater: Land, Strength: 4, Name: "Reinforce"},
-		{ID: 11, Theater: Land, Strength: 5, Name: "Armor"},
+		{ID: 7, Theater: Land, Strength: 1, Name: "Reinforce", Ability: AbilityReinforce},
+		{ID: 8, Theater: Land, Strength: 2, Name: "Ambush", Ability: AbilityAmbush},
+		{ID: 9, Theater: Land, Strength: 3, Name: "Maneuver", Ability: AbilityManeuver},
+		{ID: 10, Theater: Land, Strength: 4, Name: "Cover Fire", Ability: AbilityCoverFire},
+		{ID: 11, Theater: Land, Strength: 5, Name: "Disrupt", Ability: AbilityDisrupt},
 		{ID: 12, Theater: Land, Strength: 6, Name: "Heavy Tanks"},
 
 		// Sea Cards (13-18)
-		{ID: 13, Theater: Sea, Strength: 1, Name: "Disrupt"},
-		{ID: 14, Theater: Sea, Strength: 2, Name: "Naval Superiority"},
-		{ID: 15, Theater: Sea, Strength: 3, Name: "Redeploy"},
-		{ID: 16, Theater: Sea, Strength: 4, Name: "Escalation"},
-		{ID: 17, Theater: Sea, Strength: 5, Name: "Containment"},
-		{ID: 18, Theater: Sea, Strength: 6, Name: "Blockade"},
+		{ID: 13, Theater: Sea, Strength: 1, Name: "Transport", Ability: AbilityTransport},
+		{ID: 14, Theater: Sea, Strength: 2, Name: "Escalation", Ability: AbilityEscalation},
+		{ID: 15, Theater: Sea, Strength: 3, Name: "Maneuver", Ability: AbilityManeuver},
+		{ID: 16, Theater: Sea, Strength: 4, Name: "Redeploy", Ability: AbilityRedeploy},
+		{ID: 17, Theater: Sea, Strength: 5, Name: "Blockade", Ability: AbilityBlockade},
+		{ID: 18, Theater: Sea, Strength: 6, Name: "Super Battleship"},
 	}
 }
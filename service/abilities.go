@@ -0,0 +1,364 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/dfturn/alns/models"
+)
+
+// abilityKind distinguishes abilities that resolve once when revealed from
+// abilities that apply for as long as the card stays face-up.
+type abilityKind int
+
+const (
+	abilityKindNone abilityKind = iota
+	abilityKindInstant
+	abilityKindOngoing
+)
+
+var abilityKinds = map[models.Ability]abilityKind{
+	models.AbilitySupport:     abilityKindOngoing,
+	models.AbilityAirDrop:     abilityKindInstant,
+	models.AbilityManeuver:    abilityKindInstant,
+	models.AbilityAerodrome:   abilityKindOngoing,
+	models.AbilityContainment: abilityKindOngoing,
+	models.AbilityReinforce:   abilityKindInstant,
+	models.AbilityAmbush:      abilityKindInstant,
+	models.AbilityCoverFire:   abilityKindOngoing,
+	models.AbilityDisrupt:     abilityKindInstant,
+	models.AbilityTransport:   abilityKindInstant,
+	models.AbilityEscalation:  abilityKindOngoing,
+	models.AbilityRedeploy:    abilityKindInstant,
+	models.AbilityBlockade:    abilityKindOngoing,
+}
+
+// boardCard locates a played card on the board
+type boardCard struct {
+	Theater models.TheaterType
+	Index   int
+	Played  models.PlayedCard
+}
+
+// adjacentTheaters returns the theaters next to t in the current theater order
+func adjacentTheaters(game *models.GameState, t models.TheaterType) []models.TheaterType {
+	var adjacent []models.TheaterType
+	for i, theater := range game.TheaterOrder {
+		if theater != t {
+			continue
+		}
+		if i > 0 {
+			adjacent = append(adjacent, game.TheaterOrder[i-1])
+		}
+		if i < len(game.TheaterOrder)-1 {
+			adjacent = append(adjacent, game.TheaterOrder[i+1])
+		}
+	}
+	return adjacent
+}
+
+func isAdjacent(game *models.GameState, a, b models.TheaterType) bool {
+	for _, t := range adjacentTheaters(game, a) {
+		if t == b {
+			return true
+		}
+	}
+	return false
+}
+
+// activeAbilityCards returns every face-up card on the board with the given ability
+func activeAbilityCards(game *models.GameState, ability models.Ability) []boardCard {
+	var found []boardCard
+	for _, t := range game.TheaterOrder {
+		theater := game.Theaters[t]
+		if theater == nil {
+			continue
+		}
+		for i, pc := range theater.Cards {
+			if pc.FaceUp && pc.Card.Ability == ability {
+				found = append(found, boardCard{Theater: t, Index: i, Played: pc})
+			}
+		}
+	}
+	return found
+}
+
+// findBoardCard locates a card on the board by ID
+func findBoardCard(game *models.GameState, cardID int) (boardCard, bool) {
+	for t, theater := range game.Theaters {
+		for i, pc := range theater.Cards {
+			if pc.Card.ID == cardID {
+				return boardCard{Theater: t, Index: i, Played: pc}, true
+			}
+		}
+	}
+	return boardCard{}, false
+}
+
+// isUncovered reports whether the card at index is the top of its owner's stack
+func isUncovered(theater *models.Theater, index int) bool {
+	owner := theater.Cards[index].PlayerID
+	for i := index + 1; i < len(theater.Cards); i++ {
+		if theater.Cards[i].PlayerID == owner {
+			return false
+		}
+	}
+	return true
+}
+
+// uncoveredCards returns the uncovered cards in the given theaters. An empty
+// ownerID matches cards of either player.
+func uncoveredCards(game *models.GameState, theaters []models.TheaterType, ownerID string) []boardCard {
+	var found []boardCard
+	for _, t := range theaters {
+		theater := game.Theaters[t]
+		if theater == nil {
+			continue
+		}
+		for i, pc := range theater.Cards {
+			if ownerID != "" && pc.PlayerID != ownerID {
+				continue
+			}
+			if isUncovered(theater, i) {
+				found = append(found, boardCard{Theater: t, Index: i, Played: pc})
+			}
+		}
+	}
+	return found
+}
+
+// choiceTarget picks the candidate named by a choice, either by card ID or,
+// for face-down cards whose ID the player cannot see, by theater and owner
+func choiceTarget(cards []boardCard, choice models.AbilityChoice) (boardCard, bool) {
+	for _, bc := range cards {
+		if choice.CardID != 0 && bc.Played.Card.ID == choice.CardID {
+			return bc, true
+		}
+		if choice.CardID == 0 && bc.Theater == choice.Theater && bc.Played.PlayerID == choice.OwnerID {
+			return bc, true
+		}
+	}
+	return boardCard{}, false
+}
+
+// placeCard adds a played card to a theater, applying Containment and
+// Blockade. It returns false if the card was discarded instead.
+func (s *GameService) placeCard(game *models.GameState, theater models.TheaterType, played models.PlayedCard) bool {
+	theaterObj := game.Theaters[theater]
+
+	if !played.FaceUp && len(activeAbilityCards(game, models.AbilityContainment)) > 0 {
+		game.Trash = append(game.Trash, played.Card)
+		return false
+	}
+
+	for _, blockade := range activeAbilityCards(game, models.AbilityBlockade) {
+		if isAdjacent(game, blockade.Theater, theater) && len(theaterObj.Cards) >= 3 {
+			game.Trash = append(game.Trash, played.Card)
+			return false
+		}
+	}
+
+	theaterObj.Cards = append(theaterObj.Cards, played)
+	return true
+}
+
+// triggerAbility queues the instant ability of a card that was just played or flipped face-up
+func (s *GameService) triggerAbility(game *models.GameState, theater models.TheaterType, played models.PlayedCard) {
+	if abilityKinds[played.Card.Ability] != abilityKindInstant {
+		return
+	}
+
+	pending := models.PendingAbility{
+		Ability:      played.Card.Ability,
+		PlayerID:     played.PlayerID,
+		OwnerID:      played.PlayerID,
+		SourceCardID: played.Card.ID,
+		Theater:      theater,
+	}
+
+	switch played.Card.Ability {
+	case models.AbilityAirDrop:
+		// Nothing to choose; the effect applies on the owner's next turn.
+		// Flipped face-up on the opponent's turn, that is the very next one.
+		game.AirDropPlayerID = played.PlayerID
+		game.AirDropReady = game.CurrentPlayerID != played.PlayerID
+		return
+	case models.AbilityReinforce:
+		if len(game.Deck) == 0 {
+			return
+		}
+		revealed := game.Deck[0]
+		pending.RevealedCard = &revealed
+		pending.Optional = true
+	case models.AbilityTransport, models.AbilityRedeploy:
+		pending.Optional = true
+	case models.AbilityDisrupt:
+		// The opponent flips one of their own cards first
+		pending.PlayerID = opponentID(game, played.PlayerID)
+	}
+
+	game.PendingAbilities = append(game.PendingAbilities, pending)
+}
+
+// settleAbilities drops pending abilities that no longer have a legal choice
+func (s *GameService) settleAbilities(game *models.GameState) {
+	for len(game.PendingAbilities) > 0 {
+		top := &game.PendingAbilities[len(game.PendingAbilities)-1]
+		if hasLegalChoice(game, top) {
+			return
+		}
+		if top.Ability == models.AbilityDisrupt && top.Step == 0 {
+			top.Step = 1
+			top.PlayerID = top.OwnerID
+			continue
+		}
+		game.PendingAbilities = game.PendingAbilities[:len(game.PendingAbilities)-1]
+	}
+}
+
+// hasLegalChoice reports whether the pending ability can still do anything
+func hasLegalChoice(game *models.GameState, pending *models.PendingAbility) bool {
+	switch pending.Ability {
+	case models.AbilityAmbush:
+		return len(uncoveredCards(game, game.TheaterOrder, "")) > 0
+	case models.AbilityManeuver:
+		return len(uncoveredCards(game, adjacentTheaters(game, pending.Theater), "")) > 0
+	case models.AbilityDisrupt:
+		return len(uncoveredCards(game, game.TheaterOrder, pending.PlayerID)) > 0
+	case models.AbilityTransport:
+		for _, theater := range game.Theaters {
+			for _, pc := range theater.Cards {
+				if pc.PlayerID == pending.OwnerID {
+					return true
+				}
+			}
+		}
+		return false
+	case models.AbilityRedeploy:
+		for _, theater := range game.Theaters {
+			for _, pc := range theater.Cards {
+				if pc.PlayerID == pending.OwnerID && !pc.FaceUp {
+					return true
+				}
+			}
+		}
+		return false
+	case models.AbilityReinforce:
+		return pending.RevealedCard != nil && len(game.Deck) > 0 && game.Deck[0].ID == pending.RevealedCard.ID
+	}
+	return false
+}
+
+// flipCard turns a card over, triggering its instant ability if it is now face-up
+func (s *GameService) flipCard(game *models.GameState, target boardCard) {
+	played := &game.Theaters[target.Theater].Cards[target.Index]
+	played.FaceUp = !played.FaceUp
+	if played.FaceUp {
+		s.triggerAbility(game, target.Theater, *played)
+	}
+}
+
+// applyAbilityChoice validates and carries out a choice for the top pending ability
+func (s *GameService) applyAbilityChoice(game *models.GameState, choice models.AbilityChoice) error {
+	top := len(game.PendingAbilities) - 1
+	pending := game.PendingAbilities[top]
+	pop := func() {
+		game.PendingAbilities = game.PendingAbilities[:top]
+	}
+
+	if choice.Skip {
+		if !pending.Optional {
+			return fmt.Errorf("%w: ability is not optional", ErrInvalidMove)
+		}
+		pop()
+		return nil
+	}
+
+	switch pending.Ability {
+	case models.AbilityAmbush, models.AbilityManeuver:
+		theaters := game.TheaterOrder
+		if pending.Ability == models.AbilityManeuver {
+			theaters = adjacentTheaters(game, pending.Theater)
+		}
+		target, ok := choiceTarget(uncoveredCards(game, theaters, ""), choice)
+		if !ok {
+			return fmt.Errorf("%w: card cannot be flipped by this ability", ErrInvalidMove)
+		}
+		pop()
+		s.flipCard(game, target)
+
+	case models.AbilityDisrupt:
+		target, ok := choiceTarget(uncoveredCards(game, game.TheaterOrder, pending.PlayerID), choice)
+		if !ok {
+			return fmt.Errorf("%w: you must flip one of your own uncovered cards", ErrInvalidMove)
+		}
+		if pending.Step == 0 {
+			game.PendingAbilities[top].Step = 1
+			game.PendingAbilities[top].PlayerID = pending.OwnerID
+		} else {
+			pop()
+		}
+		s.flipCard(game, target)
+
+	case models.AbilityTransport:
+		target, ok := findBoardCard(game, choice.CardID)
+		if !ok || target.Played.PlayerID != pending.OwnerID {
+			return fmt.Errorf("%w: you must move one of your own cards", ErrInvalidMove)
+		}
+		destination := game.Theaters[choice.ToTheater]
+		if destination == nil || choice.ToTheater == target.Theater {
+			return fmt.Errorf("%w: card must move to a different theater", ErrInvalidMove)
+		}
+		pop()
+		source := game.Theaters[target.Theater]
+		source.Cards = append(source.Cards[:target.Index], source.Cards[target.Index+1:]...)
+		destination.Cards = append(destination.Cards, target.Played)
+
+	case models.AbilityRedeploy:
+		target, ok := findBoardCard(game, choice.CardID)
+		if !ok || target.Played.PlayerID != pending.OwnerID || target.Played.FaceUp {
+			return fmt.Errorf("%w: you must return one of your own face-down cards", ErrInvalidMove)
+		}
+		pop()
+		source := game.Theaters[target.Theater]
+		source.Cards = append(source.Cards[:target.Index], source.Cards[target.Index+1:]...)
+		if game.Player1.ID == pending.OwnerID {
+			game.Player1.Hand = append(game.Player1.Hand, target.Played.Card)
+		} else {
+			game.Player2.Hand = append(game.Player2.Hand, target.Played.Card)
+		}
+		game.ExtraTurnPlayerID = pending.OwnerID
+
+	case models.AbilityReinforce:
+		if game.Theaters[choice.ToTheater] == nil || !isAdjacent(game, pending.Theater, choice.ToTheater) {
+			return fmt.Errorf("%w: reinforcements must go to an adjacent theater", ErrInvalidMove)
+		}
+		pop()
+		card := game.Deck[0]
+		game.Deck = game.Deck[1:]
+		s.placeCard(game, choice.ToTheater, models.PlayedCard{
+			Card:     card,
+			FaceUp:   false,
+			PlayerID: pending.OwnerID,
+		})
+
+	default:
+		return fmt.Errorf("%w: unknown ability", ErrInvalidMove)
+	}
+
+	return nil
+}
+
+func opponentID(game *models.GameState, playerID string) string {
+	if game.Player1.ID == playerID {
+		return game.Player2.ID
+	}
+	return game.Player1.ID
+}
+
+// resetAbilityState clears ability effects left over from the previous battle
+func resetAbilityState(game *models.GameState) {
+	game.PendingAbilities = nil
+	game.AirDropPlayerID = ""
+	game.AirDropReady = false
+	game.ExtraTurnPlayerID = ""
+}
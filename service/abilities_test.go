@@ -0,0 +1,362 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+// testCard returns the card with the given ID
+func testCard(id int) models.Card {
+	for _, card := range models.AllCards() {
+		if card.ID == id {
+			return card
+		}
+	}
+	panic("unknown card")
+}
+
+func testCards(ids ...int) []models.Card {
+	cards := make([]models.Card, len(ids))
+	for i, id := range ids {
+		cards[i] = testCard(id)
+	}
+	return cards
+}
+
+// newBoard returns a detached strict game in its first battle, with empty
+// theaters in the order Air, Land, Sea, the given hands and p1 to play.
+// The deck holds every other card in ID order.
+func newBoard(hand1, hand2 []int) *models.GameState {
+	game := &models.GameState{
+		ID:              "game",
+		Player1:         models.Player{ID: "p1", Name: "Alice", Hand: testCards(hand1...)},
+		Player2:         models.Player{ID: "p2", Name: "Bob", Hand: testCards(hand2...)},
+		Trash:           []models.Card{},
+		TheaterOrder:    []models.TheaterType{models.Air, models.Land, models.Sea},
+		CurrentPlayerID: "p1",
+		FirstPlayerID:   "p1",
+		Phase:           models.PhasePlaying,
+		BattleNumber:    1,
+		Mode:            models.ModeStrict,
+		Theaters: map[models.TheaterType]*models.Theater{
+			models.Air:  {Type: models.Air, Cards: []models.PlayedCard{}},
+			models.Land: {Type: models.Land, Cards: []models.PlayedCard{}},
+			models.Sea:  {Type: models.Sea, Cards: []models.PlayedCard{}},
+		},
+	}
+
+	dealt := map[int]bool{}
+	for _, id := range append(append([]int{}, hand1...), hand2...) {
+		dealt[id] = true
+	}
+	for _, card := range models.AllCards() {
+		if !dealt[card.ID] {
+			game.Deck = append(game.Deck, card)
+		}
+	}
+	return game
+}
+
+// place puts a card from the deck straight onto the board
+func place(game *models.GameState, theater models.TheaterType, playerID string, id int, faceUp bool) {
+	for i, card := range game.Deck {
+		if card.ID == id {
+			game.Deck = append(game.Deck[:i], game.Deck[i+1:]...)
+			break
+		}
+	}
+	game.Theaters[theater].Cards = append(game.Theaters[theater].Cards, models.PlayedCard{
+		Card:     testCard(id),
+		FaceUp:   faceUp,
+		PlayerID: playerID,
+	})
+}
+
+func playAction(playerID string, id int, theater models.TheaterType, faceUp bool) models.Action {
+	return models.Action{Type: models.ActionPlayCard, PlayerID: playerID, CardID: id, Theater: theater, FaceUp: faceUp}
+}
+
+func resolveAction(playerID string, choice models.AbilityChoice) models.Action {
+	return models.Action{Type: models.ActionResolveAbility, PlayerID: playerID, Choice: &choice}
+}
+
+func mustApply(t *testing.T, game *models.GameState, actions ...models.Action) {
+	t.Helper()
+	for _, action := range actions {
+		if err := ApplyAction(game, action); err != nil {
+			t.Fatalf("%s by %s: %v", action.Type, action.PlayerID, err)
+		}
+	}
+}
+
+// boardCardAt returns the card with the given ID and the theater it is in
+func boardCardAt(t *testing.T, game *models.GameState, id int) (models.TheaterType, models.PlayedCard) {
+	t.Helper()
+	bc, ok := findBoardCard(game, id)
+	if !ok {
+		t.Fatalf("card %d is not on the board", id)
+	}
+	return bc.Theater, bc.Played
+}
+
+func hasCard(cards []models.Card, id int) bool {
+	for _, card := range cards {
+		if card.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSupportStrengthensAdjacentTheaters(t *testing.T) {
+	game := newBoard(nil, nil)
+	place(game, models.Air, "p1", 1, true)
+	place(game, models.Land, "p1", 12, true)
+	place(game, models.Sea, "p1", 18, true)
+
+	scores := ComputeTheaterScores(game)
+	if got := scores[models.Air].Player1Total; got != 1 {
+		t.Errorf("Air = %d, want 1", got)
+	}
+	if got := scores[models.Land].Player1Total; got != 9 {
+		t.Errorf("Land = %d, want 9 with Support", got)
+	}
+	if got := scores[models.Sea].Player1Total; got != 6 {
+		t.Errorf("Sea = %d, want 6 since it is not adjacent to Air", got)
+	}
+}
+
+func TestAirDropAppliesToOwnersNextTurnOnly(t *testing.T) {
+	game := newBoard([]int{2, 12, 18}, []int{6, 7, 8})
+
+	mustApply(t, game,
+		playAction("p1", 2, models.Air, true),
+		playAction("p2", 6, models.Sea, false),
+		playAction("p1", 12, models.Sea, true),
+		playAction("p2", 7, models.Sea, false),
+	)
+	if _, err := checkPlacement(game, "p1", testCard(18), models.Land, true); !errors.Is(err, ErrInvalidPlacement) {
+		t.Errorf("Air Drop still applies a turn later: %v", err)
+	}
+}
+
+func TestAirDropFlippedByOpponentAppliesNextTurn(t *testing.T) {
+	game := newBoard([]int{12, 18}, []int{8, 6})
+	place(game, models.Land, "p1", 2, false)
+	game.CurrentPlayerID = "p2"
+
+	mustApply(t, game,
+		playAction("p2", 8, models.Land, true),
+		resolveAction("p2", models.AbilityChoice{Theater: models.Land, OwnerID: "p1"}),
+	)
+	if game.CurrentPlayerID != "p1" {
+		t.Fatalf("current player = %s, want p1", game.CurrentPlayerID)
+	}
+	mustApply(t, game, playAction("p1", 12, models.Sea, true))
+	if game.AirDropPlayerID != "" {
+		t.Error("Air Drop was not used up")
+	}
+}
+
+func TestManeuverFlipsAdjacentCards(t *testing.T) {
+	game := newBoard([]int{3, 12}, []int{6, 7})
+	place(game, models.Land, "p2", 18, true)
+	place(game, models.Sea, "p2", 17, false)
+
+	mustApply(t, game, playAction("p1", 3, models.Air, true))
+	if err := ApplyAction(game, resolveAction("p1", models.AbilityChoice{CardID: 17})); !errors.Is(err, ErrInvalidMove) {
+		t.Fatalf("flipping a card in a non-adjacent theater = %v, want ErrInvalidMove", err)
+	}
+	mustApply(t, game, resolveAction("p1", models.AbilityChoice{CardID: 18}))
+
+	if _, played := boardCardAt(t, game, 18); played.FaceUp {
+		t.Error("Maneuver did not flip the card")
+	}
+	if game.CurrentPlayerID != "p2" {
+		t.Errorf("current player = %s, want p2", game.CurrentPlayerID)
+	}
+}
+
+func TestAerodromeAllowsWeakCardsAnywhere(t *testing.T) {
+	game := newBoard([]int{9, 12}, []int{6, 7})
+	place(game, models.Air, "p1", 4, true)
+
+	if err := ApplyAction(game, playAction("p1", 12, models.Sea, true)); !errors.Is(err, ErrInvalidPlacement) {
+		t.Fatalf("deploying strength 6 off-theater = %v, want ErrInvalidPlacement", err)
+	}
+	mustApply(t, game, playAction("p1", 9, models.Sea, true))
+	if theater, _ := boardCardAt(t, game, 9); theater != models.Sea {
+		t.Errorf("card went to %s, want sea", theater)
+	}
+}
+
+func TestContainmentDiscardsFaceDownCards(t *testing.T) {
+	game := newBoard([]int{12, 18}, []int{6, 7})
+	place(game, models.Air, "p2", 5, true)
+
+	mustApply(t, game, playAction("p1", 12, models.Land, false))
+	if len(game.Theaters[models.Land].Cards) != 0 {
+		t.Error("face-down card was placed despite Containment")
+	}
+	if !hasCard(game.Trash, 12) {
+		t.Error("face-down card was not discarded")
+	}
+}
+
+func TestReinforcePlaysTopCardToAdjacentTheater(t *testing.T) {
+	game := newBoard([]int{7, 12}, []int{6, 18})
+	// Put Transport on top of the deck
+	deck := testCards(13)
+	for _, card := range game.Deck {
+		if card.ID != 13 {
+			deck = append(deck, card)
+		}
+	}
+	game.Deck = deck
+
+	mustApply(t, game, playAction("p1", 7, models.Land, true))
+	pending := game.PendingAbilities[len(game.PendingAbilities)-1]
+	if pending.RevealedCard == nil || pending.RevealedCard.ID != 13 {
+		t.Fatalf("revealed card = %v, want 13", pending.RevealedCard)
+	}
+	if err := ApplyAction(game, resolveAction("p1", models.AbilityChoice{ToTheater: models.Land})); !errors.Is(err, ErrInvalidMove) {
+		t.Fatalf("reinforcing the same theater = %v, want ErrInvalidMove", err)
+	}
+	mustApply(t, game, resolveAction("p1", models.AbilityChoice{ToTheater: models.Sea}))
+
+	theater, played := boardCardAt(t, game, 13)
+	if theater != models.Sea || played.FaceUp || played.PlayerID != "p1" {
+		t.Errorf("reinforcement = %+v in %s, want p1's face-down card in sea", played, theater)
+	}
+	if game.Deck[0].ID == 13 {
+		t.Error("reinforcement was not taken from the deck")
+	}
+}
+
+func TestAmbushFlipsAnyUncoveredCard(t *testing.T) {
+	game := newBoard([]int{8, 12}, []int{6, 7})
+	place(game, models.Sea, "p2", 18, false)
+
+	mustApply(t, game,
+		playAction("p1", 8, models.Land, true),
+		resolveAction("p1", models.AbilityChoice{Theater: models.Sea, OwnerID: "p2"}),
+	)
+	if _, played := boardCardAt(t, game, 18); !played.FaceUp {
+		t.Error("Ambush did not flip the face-down card")
+	}
+}
+
+func TestCoverFireSetsCoveredCardsToFour(t *testing.T) {
+	game := newBoard(nil, nil)
+	place(game, models.Land, "p1", 12, false)
+	place(game, models.Land, "p1", 6, true)
+	place(game, models.Land, "p2", 18, true)
+	place(game, models.Land, "p1", 10, true)
+
+	scores := ComputeTheaterScores(game)
+	if got := scores[models.Land].Player1Total; got != 12 {
+		t.Errorf("Land = %d, want 12 with Cover Fire", got)
+	}
+	if got := scores[models.Land].Player2Total; got != 6 {
+		t.Errorf("opponent's Land = %d, want 6", got)
+	}
+}
+
+func TestDisruptFlipsOpponentsCardThenOwners(t *testing.T) {
+	game := newBoard([]int{11, 12}, []int{7, 8})
+	place(game, models.Sea, "p2", 18, true)
+	place(game, models.Air, "p1", 6, true)
+
+	mustApply(t, game, playAction("p1", 11, models.Land, true))
+	if acting := ActingPlayerID(game); acting != "p2" {
+		t.Fatalf("acting player = %s, want p2 to flip first", acting)
+	}
+	if err := ApplyAction(game, resolveAction("p2", models.AbilityChoice{CardID: 6})); !errors.Is(err, ErrInvalidMove) {
+		t.Fatalf("flipping the opponent's card = %v, want ErrInvalidMove", err)
+	}
+	mustApply(t, game, resolveAction("p2", models.AbilityChoice{CardID: 18}))
+	if acting := ActingPlayerID(game); acting != "p1" {
+		t.Fatalf("acting player = %s, want p1 to flip second", acting)
+	}
+	mustApply(t, game, resolveAction("p1", models.AbilityChoice{CardID: 6}))
+
+	for _, id := range []int{18, 6} {
+		if _, played := boardCardAt(t, game, id); played.FaceUp {
+			t.Errorf("card %d was not flipped", id)
+		}
+	}
+	if len(game.PendingAbilities) != 0 || game.CurrentPlayerID != "p2" {
+		t.Errorf("pending = %v, current = %s; want none and p2", game.PendingAbilities, game.CurrentPlayerID)
+	}
+}
+
+func TestTransportMovesOwnCard(t *testing.T) {
+	game := newBoard([]int{13, 18}, []int{7, 8})
+	place(game, models.Land, "p1", 12, true)
+	place(game, models.Air, "p2", 6, true)
+
+	mustApply(t, game, playAction("p1", 13, models.Sea, true))
+	if err := ApplyAction(game, resolveAction("p1", models.AbilityChoice{CardID: 6, ToTheater: models.Land})); !errors.Is(err, ErrInvalidMove) {
+		t.Fatalf("moving the opponent's card = %v, want ErrInvalidMove", err)
+	}
+	mustApply(t, game, resolveAction("p1", models.AbilityChoice{CardID: 12, ToTheater: models.Air}))
+
+	if theater, _ := boardCardAt(t, game, 12); theater != models.Air {
+		t.Errorf("card is in %s, want air", theater)
+	}
+	if len(game.Theaters[models.Land].Cards) != 0 {
+		t.Error("card was left in its old theater")
+	}
+}
+
+func TestEscalationStrengthensOwnFaceDownCards(t *testing.T) {
+	game := newBoard(nil, nil)
+	place(game, models.Sea, "p1", 14, true)
+	place(game, models.Air, "p1", 12, false)
+	place(game, models.Land, "p2", 18, false)
+
+	scores := ComputeTheaterScores(game)
+	if got := scores[models.Air].Player1Total; got != 4 {
+		t.Errorf("escalated face-down card = %d, want 4", got)
+	}
+	if got := scores[models.Land].Player2Total; got != 2 {
+		t.Errorf("opponent's face-down card = %d, want 2", got)
+	}
+}
+
+func TestRedeployReturnsCardAndGrantsExtraTurn(t *testing.T) {
+	game := newBoard([]int{16, 18}, []int{6, 7})
+	place(game, models.Air, "p1", 12, false)
+
+	mustApply(t, game,
+		playAction("p1", 16, models.Sea, true),
+		resolveAction("p1", models.AbilityChoice{CardID: 12}),
+	)
+	if !hasCard(game.Player1.Hand, 12) {
+		t.Error("Redeploy did not return the card to hand")
+	}
+	if len(game.Theaters[models.Air].Cards) != 0 {
+		t.Error("returned card is still on the board")
+	}
+	if game.CurrentPlayerID != "p1" {
+		t.Errorf("current player = %s, want p1 to take an extra turn", game.CurrentPlayerID)
+	}
+}
+
+func TestBlockadeDiscardsCardsPlayedToCrowdedAdjacentTheater(t *testing.T) {
+	game := newBoard([]int{6, 18}, []int{7, 8})
+	place(game, models.Sea, "p2", 17, true)
+	place(game, models.Land, "p1", 10, false)
+	place(game, models.Land, "p2", 11, false)
+	place(game, models.Land, "p1", 9, false)
+
+	mustApply(t, game, playAction("p1", 6, models.Land, false))
+	if got := len(game.Theaters[models.Land].Cards); got != 3 {
+		t.Errorf("Land holds %d cards, want 3", got)
+	}
+	if !hasCard(game.Trash, 6) {
+		t.Error("card played into the blockade was not discarded")
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dfturn/alns/models"
+)
+
+// act applies an action to a copy of the game while holding the game lock,
+// saves it as the next version and appends it to the game's move log. A
+// version of 0 skips the optimistic concurrency check. The returned state
+// is a snapshot that is safe to read after the lock is released.
+func (s *GameService) act(gameID string, version int, action models.Action) (*models.GameState, error) {
+	return s.actInRoom(nil, gameID, version, action)
+}
+
+// actInRoom is act for actions that also change the game's room. The room
+// is saved along with the game, or on its own if the game is unchanged.
+// Callers must hold roomsMu when room is set.
+func (s *GameService) actInRoom(room *models.Room, gameID string, version int, action models.Action) (*models.GameState, error) {
+	lock := s.gameLock(gameID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	current, err := s.loadGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && version != current.Version {
+		return nil, ErrVersionConflict
+	}
+
+	// Deals and times are recorded in the action so the log can replay
+	// them exactly
+	action.At = time.Now()
+	if action.Type == models.ActionNextBattle || action.Type == models.ActionNextGame {
+		action.Deal = nextDeal(current)
+	}
+
+	game := cloneGame(current)
+	if err := s.applyAction(game, action); err != nil {
+		if errors.Is(err, errNoChange) {
+			if room != nil {
+				if err := s.store.SaveRoom(room); err != nil {
+					return nil, err
+				}
+			}
+			return cloneGame(current), nil
+		}
+		return nil, err
+	}
+
+	if err := s.saveRoomAndGame(room, game, action); err != nil {
+		return nil, err
+	}
+	return cloneGame(game), nil
+}
+
+// applyAction carries out an action on a game. It must only depend on the
+// game and the action so that replaying the move log reproduces the game.
+func (s *GameService) applyAction(game *models.GameState, action models.Action) error {
+	wasOver := game.Phase == models.PhaseGameOver
+	if err := s.applyMove(game, action); err != nil {
+		return err
+	}
+	updateSeries(game, wasOver)
+	updateClock(game, action)
+	return nil
+}
+
+// applyMove dispatches an action to the rule that carries it out
+func (s *GameService) applyMove(game *models.GameState, action models.Action) error {
+	switch action.Type {
+	case models.ActionPlayCard:
+		return s.playCard(game, action.PlayerID, action.CardID, action.Theater, action.FaceUp)
+	case models.ActionResolveAbility:
+		if action.Choice == nil {
+			return fmt.Errorf("%w: missing ability choice", ErrInvalidMove)
+		}
+		return s.resolveAbility(game, action.PlayerID, *action.Choice)
+	case models.ActionEndTurn:
+		return s.endTurn(game, action.PlayerID)
+	case models.ActionWithdraw:
+		return s.withdraw(game, action.PlayerID)
+	case models.ActionTimeout:
+		return s.timeout(game, action.PlayerID, action.At)
+	case models.ActionLeave:
+		return s.leave(game, action.PlayerID)
+	case models.ActionUpdateScores:
+		return s.updateTheaterScores(game, action.PlayerID, action.Totals)
+	case models.ActionAcceptScores:
+		return s.acceptServerScores(game, action.PlayerID)
+	case models.ActionNextBattle:
+		return s.startNextBattle(game, action.PlayerID, action.Deal)
+	case models.ActionNextGame:
+		return s.startNextGame(game, action.PlayerID, action.Deal)
+	case models.ActionDrawCard:
+		return s.drawCard(game, action.PlayerID)
+	case models.ActionManipulateCard:
+		return s.manipulateCard(game, action.PlayerID, action.Theater, action.CardID, action.Manipulation)
+	case models.ActionDestroyCard:
+		return s.destroyCard(game, action.PlayerID, action.CardID)
+	}
+	return fmt.Errorf("%w %q", ErrUnknownAction, action.Type)
+}
+
+// newEvent returns the move log entry for an action that produced the
+// current version of the game. Event indexes follow versions, which start
+// at 1.
+func newEvent(game *models.GameState, action models.Action) models.GameEvent {
+	event := models.GameEvent{
+		Index:     game.Version - 1,
+		Version:   game.Version,
+		Timestamp: time.Now(),
+		Action:    action,
+	}
+	if action.Type == models.ActionStartGame {
+		event.Initial = cloneGame(game)
+	}
+	return event
+}
+
+// dealHands deals six cards to each player from a shuffled deck
+func dealHands(game *models.GameState, deck []models.Card) {
+	game.Deals++
+	deck = append([]models.Card(nil), deck...)
+	game.Player1.Hand = deck[:6]
+	game.Player2.Hand = deck[6:12]
+	game.Deck = deck[12:]
+}
+
+// GetEvents returns the move log of a game
+func (s *GameService) GetEvents(gameID string) ([]models.GameEvent, error) {
+	lock := s.gameLock(gameID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, err := s.loadGame(gameID); err != nil {
+		return nil, err
+	}
+	return s.store.ListEvents(gameID)
+}
+
+// ReplayGame reconstructs the state of a game right after the event at index
+func (s *GameService) ReplayGame(gameID string, index int) (*models.GameState, error) {
+	events, err := s.GetEvents(gameID)
+	if err != nil {
+		return nil, err
+	}
+	return s.replay(events, index)
+}
+
+// replay reconstructs a game from its move log, up to and including the
+// event at index
+func (s *GameService) replay(events []models.GameEvent, index int) (*models.GameState, error) {
+	if index < 0 || index >= len(events) {
+		return nil, ErrEventNotFound
+	}
+
+	if events[0].Initial == nil {
+		return nil, fmt.Errorf("%w: no initial state", ErrCorruptMoveLog)
+	}
+
+	game := cloneGame(events[0].Initial)
+	for _, event := range events[1 : index+1] {
+		if err := s.applyAction(game, event.Action); err != nil {
+			return nil, err
+		}
+		game.Version = event.Version
+	}
+	return game, nil
+}
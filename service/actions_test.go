@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestStaleVersionIsRejected(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{Mode: models.ModeSandbox})
+
+	if _, err := s.DrawCard(game.ID, game.Player1.ID, game.Version+1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("future version: err = %v, want ErrVersionConflict", err)
+	}
+	drawn, err := s.DrawCard(game.ID, game.Player1.ID, game.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DrawCard(game.ID, game.Player2.ID, game.Version); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("stale version: err = %v, want ErrVersionConflict", err)
+	}
+
+	after, err := s.GetGame(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Version != drawn.Version {
+		t.Errorf("rejected actions moved the game from version %d to %d", drawn.Version, after.Version)
+	}
+}
+
+func TestVersionZeroSkipsCheck(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{Mode: models.ModeSandbox})
+
+	version := game.Version
+	for i := 0; i < 3; i++ {
+		drawn, err := s.DrawCard(game.ID, game.Player1.ID, 0)
+		if err != nil {
+			t.Fatalf("draw %d without a version: %v", i, err)
+		}
+		if drawn.Version != version+1 {
+			t.Errorf("draw %d moved the game from version %d to %d, want one step", i, version, drawn.Version)
+		}
+		version = drawn.Version
+	}
+
+	events, err := s.GetEvents(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != version {
+		t.Errorf("move log has %d events at version %d", len(events), version)
+	}
+	for i, event := range events {
+		if event.Version != i+1 {
+			t.Errorf("event %d has version %d, want %d", i, event.Version, i+1)
+		}
+	}
+}
+
+func TestConcurrentActionsOnSameVersion(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{})
+
+	const attempts = 8
+	var wg sync.WaitGroup
+	errs := make([]error, attempts)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.Withdraw(game.ID, game.CurrentPlayerID, game.Version)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrVersionConflict):
+			t.Errorf("losing withdrawal: err = %v, want ErrVersionConflict", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d of %d withdrawals succeeded, want exactly 1", succeeded, attempts)
+	}
+
+	after, err := s.GetGame(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Version != game.Version+1 || len(after.BattleResults) != 1 {
+		t.Errorf("game after racing withdrawals: version %d with %d battles, want version %d with 1", after.Version, len(after.BattleResults), game.Version+1)
+	}
+}
+
+func TestReplayMatchesLiveGame(t *testing.T) {
+	for seed := int64(1); seed <= 60; seed++ {
+		options := models.RoomOptions{Seed: &seed}
+		if seed%2 == 0 {
+			options.TimeControl = &models.TimeControl{BankSeconds: 600, IncrementSeconds: 5}
+		}
+		s, game := newTestGame(t, options)
+
+		// Play random legal actions, keeping the state after each one
+		rng := rand.New(rand.NewSource(seed))
+		live := []*models.GameState{game}
+		for len(live) < 300 && game.Phase != models.PhaseGameOver {
+			var action models.Action
+			if game.Phase == models.PhaseScoring {
+				action = models.Action{Type: models.ActionNextBattle, PlayerID: game.Player1.ID}
+				if game.BattleWinnerID == "" {
+					action.Type = models.ActionAcceptScores
+				}
+			} else {
+				legal := LegalActions(game, ActingPlayerID(game))
+				action = legal[rng.Intn(len(legal))]
+			}
+
+			var err error
+			if game, err = s.act(game.ID, game.Version, action); err != nil {
+				t.Fatalf("seed %d: %s by %s: %v", seed, action.Type, action.PlayerID, err)
+			}
+			live = append(live, game)
+		}
+
+		for k, want := range live {
+			got, err := s.ReplayGame(game.ID, k)
+			if err != nil {
+				t.Fatalf("seed %d: ReplayGame(%d): %v", seed, k, err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(want)
+			if !bytes.Equal(gotJSON, wantJSON) {
+				t.Fatalf("seed %d: replay at %d differs from the live game:\n got %s\nwant %s", seed, k, gotJSON, wantJSON)
+			}
+		}
+	}
+}
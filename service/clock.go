@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dfturn/alns/models"
+)
+
+// validateTimeControl checks the time control of a new room and fills in
+// its defaults
+func validateTimeControl(tc *models.TimeControl) error {
+	if tc.PerMoveSeconds < 0 || tc.BankSeconds < 0 || tc.IncrementSeconds < 0 {
+		return fmt.Errorf("%w: time limits cannot be negative", ErrInvalidOptions)
+	}
+	if (tc.PerMoveSeconds > 0) == (tc.BankSeconds > 0) {
+		return fmt.Errorf("%w: time control needs either a per-move limit or a time bank", ErrInvalidOptions)
+	}
+	if tc.PerMoveSeconds > 0 && tc.IncrementSeconds > 0 {
+		return fmt.Errorf("%w: increments only apply to a time bank", ErrInvalidOptions)
+	}
+
+	switch tc.OnExpiry {
+	case "":
+		tc.OnExpiry = models.ExpiryWithdraw
+	case models.ExpiryWithdraw, models.ExpiryForfeit:
+	default:
+		return fmt.Errorf("%w: unknown expiry action", ErrInvalidOptions)
+	}
+	return nil
+}
+
+// newClock gives both players a full allowance. Nobody's time is running
+// until startClock is called.
+func newClock(game *models.GameState) *models.Clock {
+	allowance := int64(game.TimeControl.BankSeconds) * 1000
+	if game.TimeControl.PerMoveSeconds > 0 {
+		allowance = int64(game.TimeControl.PerMoveSeconds) * 1000
+	}
+
+	return &models.Clock{
+		RemainingMs: map[string]int64{
+			game.Player1.ID: allowance,
+			game.Player2.ID: allowance,
+		},
+	}
+}
+
+// updateClock charges the time since the clock started to the player it
+// was running for, then starts it for whoever has to act next. Clocks only
+// move on action timestamps so that replays reproduce them.
+func updateClock(game *models.GameState, action models.Action) {
+	clock := game.Clock
+	if clock == nil || game.TimeControl == nil || action.At.IsZero() {
+		return
+	}
+
+	if running := clock.RunningPlayerID; running != "" {
+		left := clock.RemainingMs[running] - action.At.Sub(clock.RunningSince).Milliseconds()
+		if left < 0 {
+			left = 0
+		}
+		if running == action.PlayerID && action.Type != models.ActionTimeout {
+			left += int64(game.TimeControl.IncrementSeconds) * 1000
+		}
+		clock.RemainingMs[running] = left
+	}
+
+	startClock(game, action.At)
+}
+
+// startClock runs the clock of the player who has to act, if anyone does
+func startClock(game *models.GameState, at time.Time) {
+	clock := game.Clock
+	clock.RunningPlayerID = ""
+	clock.RunningSince = time.Time{}
+	if game.Phase != models.PhasePlaying {
+		return
+	}
+
+	playerID := ActingPlayerID(game)
+	if game.TimeControl.PerMoveSeconds > 0 {
+		clock.RemainingMs[playerID] = int64(game.TimeControl.PerMoveSeconds) * 1000
+	}
+	clock.RunningPlayerID = playerID
+	clock.RunningSince = at
+}
+
+// timeout applies the room's expiry action to a player whose time is up
+func (s *GameService) timeout(game *models.GameState, playerID string, at time.Time) error {
+	player, opponent, err := resolvePlayers(game, playerID)
+	if err != nil {
+		return err
+	}
+
+	clock := game.Clock
+	if clock == nil || clock.RunningPlayerID != playerID ||
+		at.Sub(clock.RunningSince).Milliseconds() < clock.RemainingMs[playerID] {
+		return ErrTimeNotUp
+	}
+
+	if game.TimeControl.OnExpiry == models.ExpiryForfeit {
+		game.Phase = models.PhaseGameOver
+		game.ForfeitedPlayerID = playerID
+		game.WinnerID = opponent.ID
+		return nil
+	}
+
+	// Abilities left to resolve end with the battle. The player may be
+	// resolving an ability on the opponent's turn, so the turn check of
+	// an ordinary withdrawal does not apply.
+	game.PendingAbilities = nil
+	s.concede(game, player, opponent)
+	return nil
+}
+
+// remainingAt returns a copy of a clock with the running player's time
+// counted down to now
+func remainingAt(clock *models.Clock, now time.Time) *models.Clock {
+	view := &models.Clock{
+		RemainingMs:     make(map[string]int64, len(clock.RemainingMs)),
+		RunningPlayerID: clock.RunningPlayerID,
+	}
+	for playerID, left := range clock.RemainingMs {
+		view.RemainingMs[playerID] = left
+	}
+
+	if running := clock.RunningPlayerID; running != "" {
+		left := view.RemainingMs[running] - now.Sub(clock.RunningSince).Milliseconds()
+		if left < 0 {
+			left = 0
+		}
+		view.RemainingMs[running] = left
+		view.RunningSince = now
+	}
+	return view
+}
+
+// scheduleTimeout arms the timer that ends the turn of the player whose
+// clock is running. Callers must hold the game lock.
+func (s *GameService) scheduleTimeout(game *models.GameState) {
+	if timer, ok := s.timers.LoadAndDelete(game.ID); ok {
+		timer.(*time.Timer).Stop()
+	}
+
+	clock := game.Clock
+	if clock == nil || clock.RunningPlayerID == "" {
+		return
+	}
+
+	gameID, playerID, version := game.ID, clock.RunningPlayerID, game.Version
+	// The extra millisecond covers rounding, so the time is always up by
+	// the time the timer fires
+	left := time.Duration(clock.RemainingMs[playerID]+1)*time.Millisecond - time.Since(clock.RunningSince)
+	s.timers.Store(gameID, s.afterFunc(left, func() {
+		_, err := s.act(gameID, version, models.Action{Type: models.ActionTimeout, PlayerID: playerID})
+		if err != nil && !errors.Is(err, ErrVersionConflict) {
+			log.Printf("Timeout in game %s: %v", gameID, err)
+		}
+	}))
+}
+
+// ResumeClocks arms the timers of every game in the store, e.g. after the
+// server restarted with a persistent store. Time keeps running while the
+// server is down.
+func (s *GameService) ResumeClocks() error {
+	games, err := s.store.ListGames()
+	if err != nil {
+		return err
+	}
+
+	for _, game := range games {
+		lock := s.gameLock(game.ID)
+		lock.Lock()
+		s.scheduleTimeout(game)
+		lock.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dfturn/alns/models"
+)
+
+// newClockedBoard returns a detached game under a time control whose clock
+// started running for p1 at start
+func newClockedBoard(tc models.TimeControl, start time.Time) *models.GameState {
+	game := newBoard([]int{1, 7, 13}, []int{2, 8, 14})
+	game.TimeControl = &tc
+	game.Clock = newClock(game)
+	startClock(game, start)
+	return game
+}
+
+// actionAt returns an action taken at the given time
+func actionAt(action models.Action, t time.Time) models.Action {
+	action.At = t
+	return action
+}
+
+// waitForPhase polls a game until it leaves the playing phase
+func waitForPhase(t *testing.T, s *GameService, gameID string) *models.GameState {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		game, err := s.GetGame(gameID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if game.Phase != models.PhasePlaying {
+			return game
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("the clock never ran out")
+	return nil
+}
+
+func TestPerMoveExpiryWithdraws(t *testing.T) {
+	t.Parallel()
+	s, game := newTestGame(t, models.RoomOptions{
+		TimeControl: &models.TimeControl{PerMoveSeconds: 1, OnExpiry: models.ExpiryWithdraw},
+	})
+	late := game.Clock.RunningPlayerID
+
+	game = waitForPhase(t, s, game.ID)
+	if game.Phase != models.PhaseScoring || game.WithdrewPlayerID != late {
+		t.Fatalf("after expiry the game is in %s with %q withdrawn, want %s with %q withdrawn",
+			game.Phase, game.WithdrewPlayerID, models.PhaseScoring, late)
+	}
+	if result := game.BattleResults[0]; result.WithdrewPlayerID != late || result.WinnerID == late {
+		t.Errorf("battle result = %+v, want %q to have withdrawn and lost", result, late)
+	}
+}
+
+func TestBankExpiryForfeits(t *testing.T) {
+	t.Parallel()
+	s, game := newTestGame(t, models.RoomOptions{
+		TimeControl: &models.TimeControl{BankSeconds: 1, OnExpiry: models.ExpiryForfeit},
+	})
+	late := game.Clock.RunningPlayerID
+
+	game = waitForPhase(t, s, game.ID)
+	if game.Phase != models.PhaseGameOver || game.ForfeitedPlayerID != late {
+		t.Fatalf("after expiry the game is in %s with %q forfeited, want %s with %q forfeited",
+			game.Phase, game.ForfeitedPlayerID, models.PhaseGameOver, late)
+	}
+	if game.WinnerID == late || game.WinnerID == "" {
+		t.Errorf("game won by %q, want the opponent of %q", game.WinnerID, late)
+	}
+}
+
+func TestBankGrowsByIncrement(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	game := newClockedBoard(models.TimeControl{BankSeconds: 60, IncrementSeconds: 5}, start)
+
+	mustApply(t, game,
+		actionAt(playAction("p1", 1, models.Land, false), start.Add(10*time.Second)),
+		actionAt(playAction("p2", 8, models.Air, false), start.Add(12*time.Second)),
+	)
+	want := map[string]int64{"p1": 55000, "p2": 63000}
+	for playerID, ms := range want {
+		if left := game.Clock.RemainingMs[playerID]; left != ms {
+			t.Errorf("%s has %dms left, want %dms", playerID, left, ms)
+		}
+	}
+	if game.Clock.RunningPlayerID != "p1" || !game.Clock.RunningSince.Equal(start.Add(12*time.Second)) {
+		t.Errorf("clock running for %q since %v, want p1 since the last action", game.Clock.RunningPlayerID, game.Clock.RunningSince)
+	}
+}
+
+func TestClockStopsOutsidePlay(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		p2Score int
+		phase   models.GamePhase
+	}{
+		{"scoring", 0, models.PhaseScoring},
+		{"game over", winningScore - 2, models.PhaseGameOver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newClockedBoard(models.TimeControl{BankSeconds: 60}, start)
+			game.Player2.Score = tt.p2Score
+			mustApply(t, game, actionAt(models.Action{Type: models.ActionWithdraw, PlayerID: "p1"}, start.Add(time.Second)))
+
+			if game.Phase != tt.phase {
+				t.Fatalf("phase = %s, want %s", game.Phase, tt.phase)
+			}
+			if game.Clock.RunningPlayerID != "" {
+				t.Errorf("clock running for %q", game.Clock.RunningPlayerID)
+			}
+			for _, playerID := range []string{"p1", "p2"} {
+				err := ApplyAction(game, actionAt(models.Action{Type: models.ActionTimeout, PlayerID: playerID}, start.Add(time.Hour)))
+				if !errors.Is(err, ErrTimeNotUp) {
+					t.Errorf("timeout of %s = %v, want ErrTimeNotUp", playerID, err)
+				}
+			}
+
+			s := NewGameService(NewMemoryStore())
+			s.afterFunc = func(time.Duration, func()) *time.Timer {
+				t.Error("armed a timer for a stopped clock")
+				return time.NewTimer(0)
+			}
+			s.scheduleTimeout(game)
+		})
+	}
+}
+
+func TestStaleTimerDoesNothing(t *testing.T) {
+	var armed []func()
+	s := NewSeededGameService(NewMemoryStore(), 1)
+	s.afterFunc = func(_ time.Duration, f func()) *time.Timer {
+		armed = append(armed, f)
+		return time.NewTimer(time.Hour)
+	}
+	room, err := s.CreateRoom("Alice", models.RoomOptions{
+		TimeControl: &models.TimeControl{PerMoveSeconds: 60},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, game, err := s.JoinRoom(room.ID, "Bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := armed[len(armed)-1]
+
+	player := &game.Player1
+	if game.CurrentPlayerID == game.Player2.ID {
+		player = &game.Player2
+	}
+	moved, err := s.PlayCard(game.ID, player.ID, game.Version, player.Hand[0].ID, models.Air, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale()
+	after, err := s.GetGame(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Version != moved.Version || after.Phase != models.PhasePlaying {
+		t.Errorf("stale timer moved the game from version %d to %d (%s)", moved.Version, after.Version, after.Phase)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import "github.com/dfturn/alns/models"
+
+// cloneGame returns a deep copy of a game state
+func cloneGame(game *models.GameState) *models.GameState {
+	clone := *game
+	clone.Player1 = clonePlayer(game.Player1)
+	clone.Player2 = clonePlayer(game.Player2)
+	clone.Deck = append([]models.Card{}, game.Deck...)
+	clone.Trash = append([]models.Card{}, game.Trash...)
+	clone.TheaterOrder = append([]models.TheaterType(nil), game.TheaterOrder...)
+	clone.ScoreSubmissions = cloneScoreSubmissions(game.ScoreSubmissions)
+	clone.DisputedTheaters = append([]models.TheaterType(nil), game.DisputedTheaters...)
+	clone.BattleResults = cloneBattleResults(game.BattleResults)
+	clone.Series = cloneSeries(game.Series)
+	clone.SeriesHistory = cloneSeriesHistory(game.SeriesHistory)
+
+	if game.TimeControl != nil {
+		timeControl := *game.TimeControl
+		clone.TimeControl = &timeControl
+	}
+	if game.Clock != nil {
+		clock := *game.Clock
+		clock.RemainingMs = make(map[string]int64, len(game.Clock.RemainingMs))
+		for playerID, left := range game.Clock.RemainingMs {
+			clock.RemainingMs[playerID] = left
+		}
+		clone.Clock = &clock
+	}
+
+	if game.Theaters != nil {
+		clone.Theaters = make(map[models.TheaterType]*models.Theater, len(game.Theaters))
+		for t, theater := range game.Theaters {
+			clone.Theaters[t] = &models.Theater{
+				Type:  theater.Type,
+				Cards: append([]models.PlayedCard{}, theater.Cards...),
+			}
+		}
+	}
+
+	if game.TheaterScores != nil {
+		clone.TheaterScores = make(map[models.TheaterType]*models.TheaterScore, len(game.TheaterScores))
+		for t, score := range game.TheaterScores {
+			scoreCopy := *score
+			clone.TheaterScores[t] = &scoreCopy
+		}
+	}
+
+	if game.PendingAbilities != nil {
+		clone.PendingAbilities = make([]models.PendingAbility, len(game.PendingAbilities))
+		for i, pending := range game.PendingAbilities {
+			if pending.RevealedCard != nil {
+				revealed := *pending.RevealedCard
+				pending.RevealedCard = &revealed
+			}
+			clone.PendingAbilities[i] = pending
+		}
+	}
+
+	return &clone
+}
+
+func cloneScoreSubmissions(submissions map[string]map[models.TheaterType]models.TheaterScore) map[string]map[models.TheaterType]models.TheaterScore {
+	if submissions == nil {
+		return nil
+	}
+	clone := make(map[string]map[models.TheaterType]models.TheaterScore, len(submissions))
+	for playerID, totals := range submissions {
+		clone[playerID] = make(map[models.TheaterType]models.TheaterScore, len(totals))
+		for t, score := range totals {
+			clone[playerID][t] = score
+		}
+	}
+	return clone
+}
+
+func clonePlayer(player models.Player) models.Player {
+	player.Hand = append([]models.Card{}, player.Hand...)
+	return player
+}
+
+// cloneRoom returns a deep copy of a room
+func cloneRoom(room *models.Room) *models.Room {
+	clone := *room
+	if room.Player1 != nil {
+		player := clonePlayer(*room.Player1)
+		clone.Player1 = &player
+	}
+	if room.Player2 != nil {
+		player := clonePlayer(*room.Player2)
+		clone.Player2 = &player
+	}
+	if room.Options.TimeControl != nil {
+		timeControl := *room.Options.TimeControl
+		clone.Options.TimeControl = &timeControl
+	}
+	if room.Options.Seed != nil {
+		seed := *room.Options.Seed
+		clone.Options.Seed = &seed
+	}
+	if room.Seats != nil {
+		clone.Seats = make(map[string]models.Seat, len(room.Seats))
+		for playerID, seat := range room.Seats {
+			clone.Seats[playerID] = seat
+		}
+	}
+	if room.Spectators != nil {
+		clone.Spectators = make(map[string]models.Seat, len(room.Spectators))
+		for spectatorID, seat := range room.Spectators {
+			clone.Spectators[spectatorID] = seat
+		}
+	}
+	return &clone
+}
+
+func cloneBattleResults(results []models.BattleResult) []models.BattleResult {
+	if results == nil {
+		return nil
+	}
+	clone := make([]models.BattleResult, len(results))
+	for i, result := range results {
+		scores := make(map[models.TheaterType]models.TheaterScore, len(result.TheaterScores))
+		for t, score := range result.TheaterScores {
+			scores[t] = score
+		}
+		result.TheaterScores = scores
+
+		board := make([]models.Theater, len(result.Board))
+		for j, theater := range result.Board {
+			board[j] = models.Theater{Type: theater.Type, Cards: append([]models.PlayedCard{}, theater.Cards...)}
+		}
+		result.Board = board
+		clone[i] = result
+	}
+	return clone
+}
+
+func cloneSeries(series *models.Series) *models.Series {
+	if series == nil {
+		return nil
+	}
+	clone := *series
+	clone.Games = make([]models.GameResult, len(series.Games))
+	for i, result := range series.Games {
+		scores := make(map[string]int, len(result.Scores))
+		for playerID, score := range result.Scores {
+			scores[playerID] = score
+		}
+		result.Scores = scores
+		clone.Games[i] = result
+	}
+	clone.Wins = make(map[string]int, len(series.Wins))
+	for playerID, wins := range series.Wins {
+		clone.Wins[playerID] = wins
+	}
+	return &clone
+}
+
+func cloneSeriesHistory(history []models.Series) []models.Series {
+	if history == nil {
+		return nil
+	}
+	clone := make([]models.Series, len(history))
+	for i := range history {
+		clone[i] = *cloneSeries(&history[i])
+	}
+	return clone
+}
@@ -0,0 +1,73 @@
+package service
+
+import "errors"
+
+// Errors returned by the game service. Errors that carry more detail wrap
+// one of these, so compare them with errors.Is. Stores report missing
+// records with ErrNotFound.
+var (
+	ErrGameNotFound   = errors.New("game not found")
+	ErrRoomNotFound   = errors.New("room not found")
+	ErrTicketNotFound = errors.New("ticket not found")
+	ErrEventNotFound  = errors.New("event index out of range")
+
+	// ErrInvalidOptions is returned when a room is created with options
+	// that do not go together
+	ErrInvalidOptions = errors.New("invalid room options")
+	// ErrRoomUnavailable is returned when joining a room that is no longer
+	// waiting for a player
+	ErrRoomUnavailable = errors.New("room is not available")
+	ErrRoomClosed      = errors.New("room is closed")
+	ErrNotInRoom       = errors.New("player is not in this room")
+	// ErrNotRoomCreator is returned when anyone but its creator closes a room
+	ErrNotRoomCreator       = errors.New("only the player who created the room can close it")
+	ErrSpectatorsNotAllowed = errors.New("room does not allow spectators")
+	ErrTicketMatched        = errors.New("ticket has already been matched")
+	// ErrInvalidRecoveryCode is returned when a recovery code does not
+	// match any seat in the room
+	ErrInvalidRecoveryCode = errors.New("invalid recovery code")
+
+	// ErrNotInGame is returned when an action names a player who is not
+	// seated in the game
+	ErrNotInGame = errors.New("player is not in this game")
+	// ErrVersionConflict is returned when an action was made against a
+	// version of the game that is no longer current
+	ErrVersionConflict = errors.New("game has changed, reload and try again")
+	// ErrUnknownAction is returned for an action type the service does not
+	// know
+	ErrUnknownAction = errors.New("unknown action")
+	// ErrTimeNotUp is returned when a player is timed out before their
+	// time has run out
+	ErrTimeNotUp = errors.New("time has not run out")
+	// ErrCorruptMoveLog is returned when a game's move log cannot be
+	// replayed
+	ErrCorruptMoveLog = errors.New("move log is corrupt")
+
+	// ErrNotYourTurn is returned when a player acts out of turn
+	ErrNotYourTurn = errors.New("not your turn")
+	// ErrWrongPhase is returned for actions that do not belong in the
+	// current phase of the game
+	ErrWrongPhase = errors.New("wrong phase")
+	// ErrWrongMode is returned for actions the game's mode does not have
+	ErrWrongMode = errors.New("not available in this game mode")
+	// ErrAbilityPending is returned when a player acts before resolving
+	// the ability waiting on them
+	ErrAbilityPending = errors.New("resolve the pending ability first")
+	// ErrAlreadyWithdrawn is returned when a player withdraws from a battle
+	// that has already been withdrawn from
+	ErrAlreadyWithdrawn = errors.New("the battle has already been withdrawn from")
+
+	ErrCardNotInHand = errors.New("card not in hand")
+	// ErrUnknownTheater is returned when a card is played to a theater
+	// that is not on the board
+	ErrUnknownTheater = errors.New("unknown theater")
+	// ErrInvalidPlacement is returned when a card is played face-up to a
+	// theater it may not be deployed to
+	ErrInvalidPlacement = errors.New("face-up cards must be played to their own theater")
+	// ErrInvalidMove is returned for a move the rules do not allow, such as
+	// an ability choice that does not fit the ability
+	ErrInvalidMove = errors.New("invalid move")
+	// ErrInvalidScores is returned for a scoring submission that cannot be
+	// right
+	ErrInvalidScores = errors.New("invalid scores")
+)
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/dfturn/alns/models"
+)
+
+// subscriber receives views of a single game for one viewer. The channel
+// holds at most one view; a newer view replaces one that was not read yet,
+// since each view is a complete snapshot.
+type subscriber struct {
+	viewerID string
+	views    chan *models.GameView
+}
+
+// broker fans out game updates to subscribers
+type broker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*subscriber]struct{} // gameID -> subscribers
+}
+
+func newBroker() *broker {
+	return &broker{
+		subscribers: make(map[string]map[*subscriber]struct{}),
+	}
+}
+
+func (b *broker) subscribe(gameID, viewerID string) *subscriber {
+	sub := &subscriber{
+		viewerID: viewerID,
+		views:    make(chan *models.GameView, 1),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscribers[gameID] == nil {
+		b.subscribers[gameID] = make(map[*subscriber]struct{})
+	}
+	b.subscribers[gameID][sub] = struct{}{}
+	return sub
+}
+
+func (b *broker) unsubscribe(gameID string, sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subscribers[gameID], sub)
+	if len(b.subscribers[gameID]) == 0 {
+		delete(b.subscribers, gameID)
+	}
+}
+
+// close ends the streams of every subscriber of a game
+func (b *broker) close(gameID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers[gameID] {
+		close(sub.views)
+	}
+	delete(b.subscribers, gameID)
+}
+
+// publish sends every subscriber of the game its own view of the new state
+func (b *broker) publish(game *models.GameState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers[game.ID] {
+		sub.send(NewGameView(game, sub.viewerID))
+	}
+}
+
+func (sub *subscriber) send(view *models.GameView) {
+	for {
+		select {
+		case sub.views <- view:
+			return
+		default:
+		}
+		// Drop the stale view nobody has read yet
+		select {
+		case <-sub.views:
+		default:
+		}
+	}
+}
+
+// Subscribe streams viewerID's view of a game after every change. If the
+// caller has not seen the current version yet (lastVersion is older), the
+// current view is delivered immediately so reconnecting clients catch up.
+// The returned function must be called to stop the subscription. The
+// channel is closed if the game is deleted.
+func (s *GameService) Subscribe(gameID, viewerID string, lastVersion int) (<-chan *models.GameView, func(), error) {
+	lock := s.gameLock(gameID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	game, err := s.loadGame(gameID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sub := s.events.subscribe(gameID, viewerID)
+	if game.Version > lastVersion {
+		sub.send(NewGameView(game, viewerID))
+	}
+
+	return sub.views, func() { s.events.unsubscribe(gameID, sub) }, nil
+}
+
+// saveGame stores a change to a game along with the action that made it,
+// and notifies its subscribers. Callers must hold the game lock.
+func (s *GameService) saveGame(game *models.GameState, action models.Action) error {
+	return s.saveRoomAndGame(nil, game, action)
+}
+
+// saveRoomAndGame is saveGame for changes that also update the game's
+// room, which is then stored in the same write. Callers must also hold
+// roomsMu when room is set.
+func (s *GameService) saveRoomAndGame(room *models.Room, game *models.GameState, action models.Action) error {
+	game.Version++
+	event := newEvent(game, action)
+	if room != nil {
+		if err := s.store.SaveRoomAndGame(room, game, event); err != nil {
+			return err
+		}
+	} else if err := s.store.SaveGame(game, event); err != nil {
+		return err
+	}
+	s.scheduleTimeout(game)
+	s.events.publish(game)
+	return nil
+}
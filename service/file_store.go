@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dfturn/alns/models"
+)
+
+// compactAfter is how many records the log may grow by before the store
+// considers rewriting it
+const compactAfter = 10000
+
+// FileStore keeps rooms and games in memory and appends every change to a
+// single log file, so they survive a restart. Each change is one line of
+// JSON holding the new state of the room and game it touched and the
+// events it added to the game's move log, so a room and game changed
+// together are written together and a move costs one short append however
+// long the game has run. A torn last line left by a crash is dropped when
+// the store is opened. The log is rewritten down to the current state when
+// it is opened and whenever it has grown well past it.
+type FileStore struct {
+	path   string
+	mu     sync.Mutex
+	file   *os.File
+	rooms  map[string]*models.Room
+	games  map[string]*models.GameState
+	events map[string][]models.GameEvent
+
+	size     int64 // Length of the log up to its last complete record
+	appended int   // Records written since the log was last rewritten
+}
+
+// record is one line of the log
+type record struct {
+	Room          *models.Room       `json:"room,omitempty"`
+	Game          *models.GameState  `json:"game,omitempty"`
+	Events        []models.GameEvent `json:"events,omitempty"` // Appended to the game's move log
+	DeletedRoomID string             `json:"deletedRoomId,omitempty"`
+	DeletedGameID string             `json:"deletedGameId,omitempty"`
+}
+
+// NewFileStore opens the store kept in the file at path, loading any rooms
+// and games saved by a previous run. The file is created if it does not
+// exist.
+func NewFileStore(path string) (*FileStore, error) {
+	store := &FileStore{
+		path:   path,
+		rooms:  make(map[string]*models.Room),
+		games:  make(map[string]*models.GameState),
+		events: make(map[string][]models.GameEvent),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			// The last write was cut short
+			break
+		}
+		var rec record
+		if err := json.Unmarshal(data[:end], &rec); err != nil {
+			return nil, err
+		}
+		store.apply(rec)
+		data = data[end+1:]
+	}
+
+	// A game is always saved after its room, so one without a room is left
+	// over from a room that was deleted
+	for gameID, game := range store.games {
+		if _, ok := store.rooms[game.RoomID]; !ok {
+			delete(store.games, gameID)
+			delete(store.events, gameID)
+		}
+	}
+
+	if err := store.compact(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Close closes the log file
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// apply carries out a record on the in-memory state
+func (f *FileStore) apply(rec record) {
+	if rec.Room != nil {
+		f.rooms[rec.Room.ID] = rec.Room
+	}
+	if rec.Game != nil {
+		f.games[rec.Game.ID] = rec.Game
+		f.events[rec.Game.ID] = append(f.events[rec.Game.ID], rec.Events...)
+	}
+	if rec.DeletedRoomID != "" {
+		delete(f.rooms, rec.DeletedRoomID)
+	}
+	if rec.DeletedGameID != "" {
+		delete(f.games, rec.DeletedGameID)
+		delete(f.events, rec.DeletedGameID)
+	}
+}
+
+// write appends a record to the log and carries it out. Callers must hold
+// mu.
+func (f *FileStore) write(rec record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if _, err := f.file.Write(data); err != nil {
+		// Cut off whatever part of the record made it to the file, so that
+		// the next record starts on a line of its own
+		f.file.Truncate(f.size)
+		return err
+	}
+	if err := f.file.Sync(); err != nil {
+		return err
+	}
+	f.size += int64(len(data))
+	f.apply(rec)
+
+	// The record is saved either way; a rewrite that fails leaves the log
+	// as it was and is tried again on the next write
+	f.appended++
+	if f.appended > compactAfter && f.appended > 4*(len(f.rooms)+len(f.games)) {
+		f.compact()
+	}
+	return nil
+}
+
+// compact replaces the log with one record per room and game. The new log
+// is written to a temporary file that is renamed over the old one, so a
+// crash mid-write leaves the old log in place. Callers must hold mu, or be
+// opening the store.
+func (f *FileStore) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := f.writeSnapshot(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// The new log is opened before it takes the old one's place, so no
+	// write can go to a file that has just been replaced
+	file, err := os.OpenFile(tmp.Name(), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		file.Close()
+		return err
+	}
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file = file
+	f.size = info.Size()
+	f.appended = 0
+	return nil
+}
+
+// writeSnapshot writes a record for every room and every game, with its
+// whole move log
+func (f *FileStore) writeSnapshot(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	for _, room := range f.rooms {
+		if err := encoder.Encode(record{Room: room}); err != nil {
+			return err
+		}
+	}
+	for gameID, game := range f.games {
+		if err := encoder.Encode(record{Game: game, Events: f.events[gameID]}); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+// GetRoom retrieves a room by ID
+func (f *FileStore) GetRoom(roomID string) (*models.Room, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	room, ok := f.rooms[roomID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return room, nil
+}
+
+// SaveRoom stores a room
+func (f *FileStore) SaveRoom(room *models.Room) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.write(record{Room: room})
+}
+
+// DeleteRoom removes a room
+func (f *FileStore) DeleteRoom(roomID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.write(record{DeletedRoomID: roomID})
+}
+
+// ListRooms returns every stored room
+func (f *FileStore) ListRooms() ([]*models.Room, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rooms := make([]*models.Room, 0, len(f.rooms))
+	for _, room := range f.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms, nil
+}
+
+// GetGame retrieves a game by ID
+func (f *FileStore) GetGame(gameID string) (*models.GameState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	game, ok := f.games[gameID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return game, nil
+}
+
+// SaveGame stores a game and appends an event to its move log
+func (f *FileStore) SaveGame(game *models.GameState, event models.GameEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.write(record{Game: game, Events: []models.GameEvent{event}})
+}
+
+// SaveRoomAndGame stores a room along with a game and its event in a
+// single record
+func (f *FileStore) SaveRoomAndGame(room *models.Room, game *models.GameState, event models.GameEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.write(record{Room: room, Game: game, Events: []models.GameEvent{event}})
+}
+
+// DeleteGame removes a game and its move log
+func (f *FileStore) DeleteGame(gameID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.write(record{DeletedGameID: gameID})
+}
+
+// ListGames returns every stored game
+func (f *FileStore) ListGames() ([]*models.GameState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	games := make([]*models.GameState, 0, len(f.games))
+	for _, game := range f.games {
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+// ListEvents returns a copy of a game's move log
+func (f *FileStore) ListEvents(gameID string) ([]models.GameEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]models.GameEvent(nil), f.events[gameID]...), nil
+}
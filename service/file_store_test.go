@@ -0,0 +1,183 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestFileStoreReloadsGamesWithTheirMoveLogs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alns.log")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSeededGameService(store, 1)
+	room, err := s.CreateRoom("Alice", models.RoomOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, game, err := s.JoinRoom(room.ID, "Bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game, err = s.Withdraw(game.ID, game.CurrentPlayerID, game.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := reopened.GetGame(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != game.Version {
+		t.Errorf("reloaded version %d, want %d", saved.Version, game.Version)
+	}
+	events, err := reopened.ListEvents(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != game.Version {
+		t.Errorf("reloaded %d events for version %d", len(events), game.Version)
+	}
+
+	replayed, err := NewSeededGameService(reopened, 1).ReplayGame(game.ID, len(events)-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replayed.BattleResults, game.BattleResults) {
+		t.Error("replaying the reloaded move log does not reproduce the game")
+	}
+	if saved, err := reopened.GetRoom(room.ID); err != nil || saved.GameID != game.ID {
+		t.Errorf("room was not reloaded with its game: %+v, %v", saved, err)
+	}
+}
+
+func TestFileStoreAppendsOneRecordPerChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alns.log")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSeededGameService(store, 1)
+	room, err := s.CreateRoom("Alice", models.RoomOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, game, err := s.JoinRoom(room.ID, "Bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Creating the room is one record; the room joined and the game dealt
+	// are another
+	if lines := logLines(t, path); len(lines) != 2 {
+		t.Fatalf("log has %d records after creating and joining a room, want 2", len(lines))
+	}
+
+	before := logLines(t, path)
+	if _, err := s.Withdraw(game.ID, game.CurrentPlayerID, game.Version); err != nil {
+		t.Fatal(err)
+	}
+	after := logLines(t, path)
+	if len(after) != len(before)+1 {
+		t.Fatalf("a move wrote %d records, want 1", len(after)-len(before))
+	}
+	for i := range before {
+		if !bytes.Equal(after[i], before[i]) {
+			t.Errorf("a move rewrote record %d of the log", i)
+		}
+	}
+}
+
+func TestFileStoreDropsTornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alns.log")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveRoom(&models.Room{ID: "ROOM"}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// A crash part way through the next write
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"room":{"id":"OTH`)
+	file.Close()
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening after a torn write: %v", err)
+	}
+	if _, err := reopened.GetRoom("ROOM"); err != nil {
+		t.Errorf("room saved before the torn write was lost: %v", err)
+	}
+	if err := reopened.SaveRoom(&models.Room{ID: "NEXT"}); err != nil {
+		t.Fatal(err)
+	}
+	reopened.Close()
+	if _, err := NewFileStore(path); err != nil {
+		t.Errorf("log written after a torn write does not load: %v", err)
+	}
+}
+
+func TestFileStoreDeletes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alns.log")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game := &models.GameState{ID: "game", RoomID: "ROOM", Version: 1}
+	if err := store.SaveRoomAndGame(&models.Room{ID: "ROOM"}, game, models.GameEvent{Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteGame(game.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteRoom("ROOM"); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.GetGame(game.ID); err != ErrNotFound {
+		t.Errorf("deleted game was reloaded: %v", err)
+	}
+	if _, err := reopened.GetRoom("ROOM"); err != ErrNotFound {
+		t.Errorf("deleted room was reloaded: %v", err)
+	}
+	if events, _ := reopened.ListEvents(game.ID); len(events) != 0 {
+		t.Errorf("deleted move log was reloaded with %d events", len(events))
+	}
+	if lines := logLines(t, path); len(lines) != 0 {
+		t.Errorf("reopening left %d records of deleted rooms and games", len(lines))
+	}
+}
+
+// logLines returns the records in a store's log
+func logLines(t *testing.T, path string) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
 	"sync"
 	"time"
@@ -12,15 +13,34 @@ import (
 
 // GameService handles game logic and state management
 type GameService struct {
-	rooms sync.Map // map[string]*models.Room
-	games sync.Map // map[string]*models.GameState
-	rand  *rand.Rand
+	store   Store
+	locks   sync.Map // map[string]*sync.Mutex, one per game
+	roomsMu sync.Mutex
+	rand    *rand.Rand
+	randMu  sync.Mutex
+	events  *broker
+	timers  sync.Map // map[string]*time.Timer, the running clock of each game
+	queue   matchQueue
+
+	afterFunc func(time.Duration, func()) *time.Timer // Arms the timers; time.AfterFunc outside of tests
 }
 
-// NewGameService creates a new game service
-func NewGameService() *GameService {
+// NewGameService creates a new game service backed by store. Rooms and
+// games in the store are treated as immutable; changes are saved as copies.
+func NewGameService(store Store) *GameService {
+	return NewSeededGameService(store, time.Now().UnixNano())
+}
+
+// NewSeededGameService creates a game service whose room codes, and seeds
+// for rooms created without one, are drawn from seed. Services with the
+// same seed that receive the same calls in the same order deal the same
+// games.
+func NewSeededGameService(store Store, seed int64) *GameService {
 	return &GameService{
-		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		store:     store,
+		rand:      rand.New(rand.NewSource(seed)),
+		events:    newBroker(),
+		afterFunc: time.AfterFunc,
 	}
 }
 
@@ -29,18 +49,37 @@ func (s *GameService) generateRoomCode() string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	code := make([]byte, 6)
 	for i := range code {
-		code[i] = charset[s.rand.Intn(len(charset))]
+		code[i] = charset[s.randIntn(len(charset))]
 	}
 	return string(code)
 }
 
-// CreateRoom creates a new room for players to join
-func (s *GameService) CreateRoom(playerName string) (*models.Room, error) {
+// CreateRoom creates a new room for players to join. Rooms created with a
+// seed are private, since anyone who knows the seed knows every hand. So
+// are bot rooms, which only their bot can join.
+func (s *GameService) CreateRoom(playerName string, options models.RoomOptions) (*models.Room, error) {
+	if err := validateRoomOptions(&options); err != nil {
+		return nil, err
+	}
+
+	if options.Seed == nil {
+		seed := s.randInt63()
+		options.Seed = &seed
+	} else {
+		options.Private = true
+	}
+	if options.Opponent == models.OpponentBot {
+		options.Private = true
+	}
+
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+
 	// Generate unique room code
 	var roomID string
 	for {
 		roomID = s.generateRoomCode()
-		if _, exists := s.rooms.Load(roomID); !exists {
+		if _, err := s.store.GetRoom(roomID); errors.Is(err, ErrNotFound) {
 			break
 		}
 	}
@@ -55,25 +94,92 @@ func (s *GameService) CreateRoom(playerName string) (*models.Room, error) {
 	}
 
 	room := &models.Room{
-		ID:      roomID,
-		Player1: player,
-		Status:  models.RoomStatusWaiting,
+		ID:        roomID,
+		Player1:   player,
+		Status:    models.RoomStatusWaiting,
+		Options:   options,
+		UpdatedAt: time.Now(),
+		Seats:     map[string]models.Seat{playerID: newSeat()},
+	}
+
+	if err := s.store.SaveRoom(room); err != nil {
+		return nil, err
+	}
+	return cloneRoom(room), nil
+}
+
+// validateRoomOptions checks the options of a new room and fills in their
+// defaults
+func validateRoomOptions(options *models.RoomOptions) error {
+	switch options.Mode {
+	case "":
+		options.Mode = models.ModeStrict
+	case models.ModeStrict, models.ModeSandbox:
+	default:
+		return fmt.Errorf("%w: unknown game mode", ErrInvalidOptions)
+	}
+
+	switch options.Opponent {
+	case "":
+		options.Opponent = models.OpponentHuman
+	case models.OpponentHuman:
+	case models.OpponentBot:
+		// Bots only know how to take strict turns
+		if options.Mode != models.ModeStrict {
+			return fmt.Errorf("%w: bots only play strict games", ErrInvalidOptions)
+		}
+	default:
+		return fmt.Errorf("%w: unknown opponent", ErrInvalidOptions)
+	}
+
+	if options.TimeControl != nil {
+		// Only strict games have a player who has to act next
+		if options.Mode != models.ModeStrict {
+			return fmt.Errorf("%w: time controls only apply to strict games", ErrInvalidOptions)
+		}
+		timeControl := *options.TimeControl
+		if err := validateTimeControl(&timeControl); err != nil {
+			return err
+		}
+		options.TimeControl = &timeControl
+	}
+
+	if err := validateBestOf(options); err != nil {
+		return err
 	}
 
-	s.rooms.Store(roomID, room)
-	return room, nil
+	if options.SpectatorDelay < 0 {
+		return fmt.Errorf("%w: spectator delay cannot be negative", ErrInvalidOptions)
+	}
+	return nil
 }
 
-// JoinRoom allows a second player to join an existing room
+// JoinRoom allows a second player to join an existing room. The second
+// seat of a bot room is kept for its bot.
 func (s *GameService) JoinRoom(roomID, playerName string) (*models.Room, *models.GameState, error) {
-	value, ok := s.rooms.Load(roomID)
-	if !ok {
-		return nil, nil, errors.New("room not found")
+	return s.joinRoom(roomID, playerName, models.OpponentHuman)
+}
+
+// SeatBot seats a bot as the second player of a bot room
+func (s *GameService) SeatBot(roomID, botName string) (*models.Room, *models.GameState, error) {
+	return s.joinRoom(roomID, botName, models.OpponentBot)
+}
+
+func (s *GameService) joinRoom(roomID, playerName string, opponent models.OpponentType) (*models.Room, *models.GameState, error) {
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+
+	stored, err := s.loadRoom(roomID)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	room := value.(*models.Room)
+	room := cloneRoom(stored)
 	if room.Status != models.RoomStatusWaiting {
-		return nil, nil, errors.New("room is not available")
+		return nil, nil, ErrRoomUnavailable
+	}
+	if room.Options.Opponent != opponent {
+		return nil, nil, fmt.Errorf("%w: the room is for a %s opponent", ErrRoomUnavailable, room.Options.Opponent)
 	}
 
 	playerID := uuid.New().String()
@@ -86,76 +192,108 @@ func (s *GameService) JoinRoom(roomID, playerName string) (*models.Room, *models
 
 	room.Player2 = player
 	room.Status = models.RoomStatusFull
+	room.Seats[playerID] = newSeat()
 
-	// Start the game
-	game, err := s.startNewGame(room)
-	if err != nil {
-		return nil, nil, err
-	}
-
+	// Start the game. The room is saved with it, so neither is ever
+	// stored without the other.
+	game := s.newGame(room)
 	room.GameID = game.ID
 	room.Status = models.RoomStatusPlaying
-	s.rooms.Store(roomID, room)
+	room.UpdatedAt = time.Now()
+	if err := s.saveRoomAndGame(room, game, models.Action{Type: models.ActionStartGame}); err != nil {
+		return nil, nil, err
+	}
 
-	return room, game, nil
+	return cloneRoom(room), cloneGame(game), nil
 }
 
-// GetRoom retrieves a room by ID
+// GetRoom retrieves a snapshot of a room by ID
 func (s *GameService) GetRoom(roomID string) (*models.Room, error) {
-	value, ok := s.rooms.Load(roomID)
-	if !ok {
-		return nil, errors.New("room not found")
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	return cloneRoom(room), nil
+}
+
+// ListRooms returns snapshots of every room
+func (s *GameService) ListRooms() ([]*models.Room, error) {
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+
+	rooms, err := s.store.ListRooms()
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*models.Room, len(rooms))
+	for i, room := range rooms {
+		snapshots[i] = cloneRoom(room)
 	}
-	return value.(*models.Room), nil
+	return snapshots, nil
 }
 
-// GetGame retrieves a game by ID
+// loadRoom returns the stored room. Callers must hold roomsMu.
+func (s *GameService) loadRoom(roomID string) (*models.Room, error) {
+	room, err := s.store.GetRoom(roomID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrRoomNotFound
+	}
+	return room, err
+}
+
+// GetGame retrieves a snapshot of a game by ID
 func (s *GameService) GetGame(gameID string) (*models.GameState, error) {
-	value, ok := s.games.Load(gameID)
-	if !ok {
-		return nil, errors.New("game not found")
+	lock := s.gameLock(gameID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	game, err := s.loadGame(gameID)
+	if err != nil {
+		return nil, err
 	}
-	return value.(*models.GameState), nil
+	return cloneGame(game), nil
 }
 
-// startNewGame initializes a new game
-func (s *GameService) startNewGame(room *models.Room) (*models.GameState, error) {
+// newGame deals the first game of a room that has both its players
+func (s *GameService) newGame(room *models.Room) *models.GameState {
 	gameID := uuid.New().String()
 
+	var seed int64
+	if room.Options.Seed != nil {
+		seed = *room.Options.Seed
+	}
+
 	// Randomly choose first player
 	firstPlayerID := room.Player1.ID
-	if s.rand.Intn(2) == 1 {
+	if firstPlayerRand(seed).Intn(2) == 1 {
 		firstPlayerID = room.Player2.ID
 	}
 
-	// Shuffle and deal cards
-	deck := s.shuffleDeck(models.AllCards())
-	player1Hand := deck[:6]
-	player2Hand := deck[6:12]
-	remainingDeck := deck[12:]
-
 	game := &models.GameState{
 		ID:     gameID,
 		RoomID: room.ID,
 		Player1: models.Player{
 			ID:    room.Player1.ID,
 			Name:  room.Player1.Name,
-			Hand:  player1Hand,
 			Score: 0,
 		},
 		Player2: models.Player{
 			ID:    room.Player2.ID,
 			Name:  room.Player2.Name,
-			Hand:  player2Hand,
 			Score: 0,
 		},
-		Deck:            remainingDeck,
 		Trash:           []models.Card{},
 		TheaterOrder:    []models.TheaterType{models.Air, models.Land, models.Sea},
 		CurrentPlayerID: firstPlayerID,
 		FirstPlayerID:   firstPlayerID,
 		Phase:           models.PhasePlaying,
+		Mode:            room.Options.Mode,
+		Seed:            seed,
 		BattleNumber:    1,
+		Series:          newSeries(room.Options.BestOf),
 		Theaters: map[models.TheaterType]*models.Theater{
 			models.Air:  {Type: models.Air, Cards: []models.PlayedCard{}},
 			models.Land: {Type: models.Land, Cards: []models.PlayedCard{}},
@@ -163,45 +301,49 @@ func (s *GameService) startNewGame(room *models.Room) (*models.GameState, error)
 		},
 	}
 
-	s.games.Store(gameID, game)
-	return game, nil
-}
+	// Shuffle and deal cards
+	dealHands(game, nextDeal(game))
 
-// shuffleDeck shuffles a deck of cards
-func (s *GameService) shuffleDeck(cards []models.Card) []models.Card {
-	shuffled := make([]models.Card, len(cards))
-	copy(shuffled, cards)
+	if room.Options.TimeControl != nil {
+		timeControl := *room.Options.TimeControl
+		game.TimeControl = &timeControl
+		game.Clock = newClock(game)
+		startClock(game, time.Now())
+	}
+	return game
+}
 
-	s.rand.Shuffle(len(shuffled), func(i, j int) {
-		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+// PlayCard plays a card from a player's hand to a theater
+func (s *GameService) PlayCard(gameID, playerID string, version int, cardID int, theater models.TheaterType, faceUp bool) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{
+		Type:     models.ActionPlayCard,
+		PlayerID: playerID,
+		CardID:   cardID,
+		Theater:  theater,
+		FaceUp:   faceUp,
 	})
-
-	return shuffled
 }
 
-// PlayCard plays a card from a player's hand to a theater
-func (s *GameService) PlayCard(gameID, playerID string, cardID int, theater models.TheaterType, faceUp bool) (*models.GameState, error) {
-	game, err := s.GetGame(gameID)
+// playCard plays a card from a hand to a theater
+func (s *GameService) playCard(game *models.GameState, playerID string, cardID int, theater models.TheaterType, faceUp bool) error {
+	player, _, err := resolvePlayers(game, playerID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if game.Phase != models.PhasePlaying {
-		return nil, errors.New("game is not in playing phase")
+		return fmt.Errorf("%w: game is not in playing phase", ErrWrongPhase)
 	}
 
 	if game.CurrentPlayerID != playerID {
-		return nil, errors.New("not your turn")
+		return ErrNotYourTurn
 	}
 
-	// Find and remove card from player's hand
-	var player *models.Player
-	if game.Player1.ID == playerID {
-		player = &game.Player1
-	} else {
-		player = &game.Player2
+	if len(game.PendingAbilities) > 0 {
+		return ErrAbilityPending
 	}
 
+	// Find and remove card from player's hand
 	cardIndex := -1
 	var card models.Card
 	for i, c := range player.Hand {
@@ -213,7 +355,16 @@ func (s *GameService) PlayCard(gameID, playerID string, cardID int, theater mode
 	}
 
 	if cardIndex == -1 {
-		return nil, errors.New("card not in hand")
+		return ErrCardNotInHand
+	}
+
+	usesAirDrop, err := checkPlacement(game, playerID, card, theater, faceUp)
+	if err != nil {
+		return err
+	}
+	if usesAirDrop {
+		game.AirDropPlayerID = ""
+		game.AirDropReady = false
 	}
 
 	// Remove card from hand
@@ -226,257 +377,182 @@ func (s *GameService) PlayCard(gameID, playerID string, cardID int, theater mode
 		PlayerID: playerID,
 	}
 
-	theaterObj := game.Theaters[theater]
-	theaterObj.Cards = append(theaterObj.Cards, playedCard)
-
-	// Don't switch turns - player must explicitly end turn
-
-	// Update the player in the game state
-	if game.Player1.ID == playerID {
-		game.Player1 = *player
-	} else {
-		game.Player2 = *player
+	// Cards played face-up trigger their instant ability, unless an
+	// ongoing ability discarded them on the way in
+	if s.placeCard(game, theater, playedCard) && faceUp {
+		s.triggerAbility(game, theater, playedCard)
 	}
+	s.settleAbilities(game)
+	s.checkBattleEnd(game)
+	s.finishAction(game)
 
-	// Check if both players are out of cards
-	if len(game.Player1.Hand) == 0 && len(game.Player2.Hand) == 0 {
-		game.Phase = models.PhaseScoring
-		game.TheaterScores = make(map[models.TheaterType]*models.TheaterScore)
-	}
-
-	s.games.Store(gameID, game)
-	return game, nil
+	return nil
 }
 
 // EndTurn ends the current player's turn
-func (s *GameService) EndTurn(gameID, playerID string) (*models.GameState, error) {
-	game, err := s.GetGame(gameID)
-	if err != nil {
-		return nil, err
-	}
-
-	if game.Phase != models.PhasePlaying {
-		return nil, errors.New("game is not in playing phase")
-	}
-
-	if game.CurrentPlayerID != playerID {
-		return nil, errors.New("not your turn")
-	}
-
-	// Switch to other player
-	if game.CurrentPlayerID == game.Player1.ID {
-		game.CurrentPlayerID = game.Player2.ID
-	} else {
-		game.CurrentPlayerID = game.Player1.ID
-	}
-
-	s.games.Store(gameID, game)
-	return game, nil
+func (s *GameService) EndTurn(gameID, playerID string, version int) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{Type: models.ActionEndTurn, PlayerID: playerID})
 }
 
-// DrawCard draws one card from the deck
-func (s *GameService) DrawCard(gameID, playerID string) (*models.GameState, error) {
-	game, err := s.GetGame(gameID)
-	if err != nil {
-		return nil, err
+// endTurn passes the turn to the other player
+func (s *GameService) endTurn(game *models.GameState, playerID string) error {
+	if _, _, err := resolvePlayers(game, playerID); err != nil {
+		return err
 	}
 
 	if game.Phase != models.PhasePlaying {
-		return nil, errors.New("game is not in playing phase")
+		return fmt.Errorf("%w: game is not in playing phase", ErrWrongPhase)
 	}
 
 	if game.CurrentPlayerID != playerID {
-		return nil, errors.New("not your turn")
+		return ErrNotYourTurn
 	}
 
-	if len(game.Deck) == 0 {
-		return nil, errors.New("no cards left in deck")
+	if game.Mode != models.ModeSandbox {
+		return fmt.Errorf("%w: turns pass automatically in strict mode", ErrWrongMode)
 	}
 
-	// Draw top card from deck
-	card := game.Deck[0]
-	game.Deck = game.Deck[1:]
-
-	// Add to player's hand
-	if game.Player1.ID == playerID {
-		game.Player1.Hand = append(game.Player1.Hand, card)
-	} else {
-		game.Player2.Hand = append(game.Player2.Hand, card)
+	if len(game.PendingAbilities) > 0 {
+		return ErrAbilityPending
 	}
 
-	s.games.Store(gameID, game)
-	return game, nil
+	s.passTurn(game)
+	return nil
 }
 
-// ManipulateCard allows flipping, destroying, or returning a card to hand
-func (s *GameService) ManipulateCard(gameID, playerID string, theater models.TheaterType, cardID int, action string) (*models.GameState, error) {
-	game, err := s.GetGame(gameID)
-	if err != nil {
-		return nil, err
+// finishAction passes the turn in strict mode once the current player's
+// action, and every ability it triggered, has been resolved
+func (s *GameService) finishAction(game *models.GameState) {
+	if game.Mode == models.ModeSandbox || game.Phase != models.PhasePlaying || len(game.PendingAbilities) > 0 {
+		return
 	}
+	s.passTurn(game)
+}
 
-	if game.Phase != models.PhasePlaying {
-		return nil, errors.New("game is not in playing phase")
-	}
+// passTurn hands the turn to the other player, unless Redeploy granted the
+// current player another turn or the other player has no cards left to play
+func (s *GameService) passTurn(game *models.GameState) {
+	playerID := game.CurrentPlayerID
 
-	if game.CurrentPlayerID != playerID {
-		return nil, errors.New("not your turn")
+	// Air Drop applies to the turn after it was played, then expires
+	if game.AirDropPlayerID == playerID {
+		if game.AirDropReady {
+			game.AirDropPlayerID = ""
+			game.AirDropReady = false
+		} else {
+			game.AirDropReady = true
+		}
 	}
 
-	theaterObj := game.Theaters[theater]
-	if len(theaterObj.Cards) == 0 {
-		return nil, errors.New("no cards in this theater")
+	// Redeploy grants the player another turn
+	if game.ExtraTurnPlayerID == playerID {
+		game.ExtraTurnPlayerID = ""
+		return
 	}
 
-	// Determine target card
-	targetIndex := -1
-	var targetCard *models.PlayedCard
-	if cardID != 0 {
-		for i := len(theaterObj.Cards) - 1; i >= 0; i-- {
-			if theaterObj.Cards[i].Card.ID == cardID {
-				targetIndex = i
-				targetCard = &theaterObj.Cards[i]
-				break
-			}
-		}
-		if targetIndex == -1 {
-			return nil, errors.New("card not found in theater")
-		}
-
-		// Ensure the selected card is the top card for that player
-		for i := len(theaterObj.Cards) - 1; i > targetIndex; i-- {
-			if theaterObj.Cards[i].PlayerID == targetCard.PlayerID {
-				return nil, errors.New("card is not the top of that player's stack")
-			}
-		}
-	} else {
-		targetIndex = len(theaterObj.Cards) - 1
-		targetCard = &theaterObj.Cards[targetIndex]
+	// Switch to other player
+	nextPlayer := &game.Player1
+	if game.CurrentPlayerID == game.Player1.ID {
+		nextPlayer = &game.Player2
 	}
-
-	switch action {
-	case "flip":
-		// Flip the card
-		targetCard.FaceUp = !targetCard.FaceUp
-
-	case "destroy":
-		destroyed := *targetCard
-		// Remove the card from theater
-		theaterObj.Cards = append(theaterObj.Cards[:targetIndex], theaterObj.Cards[targetIndex+1:]...)
-		game.Trash = append(game.Trash, destroyed.Card)
-
-	case "return":
-		// Return card to owner's hand
-		card := targetCard.Card
-		theaterObj.Cards = append(theaterObj.Cards[:targetIndex], theaterObj.Cards[targetIndex+1:]...)
-
-		// Add back to owner's hand
-		if targetCard.PlayerID == game.Player1.ID {
-			game.Player1.Hand = append(game.Player1.Hand, card)
-		} else {
-			game.Player2.Hand = append(game.Player2.Hand, card)
-		}
-
-	default:
-		return nil, errors.New("invalid action")
+	if game.Mode != models.ModeSandbox && len(nextPlayer.Hand) == 0 {
+		return
 	}
+	game.CurrentPlayerID = nextPlayer.ID
+}
 
-	s.games.Store(gameID, game)
-	return game, nil
+// ResolveAbility applies a player's choice to the pending instant ability
+func (s *GameService) ResolveAbility(gameID, playerID string, version int, choice models.AbilityChoice) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{Type: models.ActionResolveAbility, PlayerID: playerID, Choice: &choice})
 }
 
-// DestroyCard removes a card from the current player's hand and places it in the trash
-func (s *GameService) DestroyCard(gameID, playerID string, cardID int) (*models.GameState, error) {
-	game, err := s.GetGame(gameID)
-	if err != nil {
-		return nil, err
+// resolveAbility applies a choice to the pending ability
+func (s *GameService) resolveAbility(game *models.GameState, playerID string, choice models.AbilityChoice) error {
+	if _, _, err := resolvePlayers(game, playerID); err != nil {
+		return err
 	}
 
 	if game.Phase != models.PhasePlaying {
-		return nil, errors.New("game is not in playing phase")
+		return fmt.Errorf("%w: game is not in playing phase", ErrWrongPhase)
 	}
 
-	if game.CurrentPlayerID != playerID {
-		return nil, errors.New("not your turn")
+	if len(game.PendingAbilities) == 0 {
+		return fmt.Errorf("%w: no ability to resolve", ErrWrongPhase)
 	}
 
-	var player *models.Player
-	if game.Player1.ID == playerID {
-		player = &game.Player1
-	} else {
-		player = &game.Player2
+	if game.PendingAbilities[len(game.PendingAbilities)-1].PlayerID != playerID {
+		return fmt.Errorf("%w: waiting for the other player to resolve an ability", ErrNotYourTurn)
 	}
 
-	index := -1
-	for i, c := range player.Hand {
-		if c.ID == cardID {
-			index = i
-			break
-		}
+	if err := s.applyAbilityChoice(game, choice); err != nil {
+		return err
 	}
+	s.settleAbilities(game)
+	s.checkBattleEnd(game)
+	s.finishAction(game)
 
-	if index == -1 {
-		return nil, errors.New("card not in hand")
-	}
-
-	destroyedCard := player.Hand[index]
-	player.Hand = append(player.Hand[:index], player.Hand[index+1:]...)
-	game.Trash = append(game.Trash, destroyedCard)
+	return nil
+}
 
-	if game.Player1.ID == playerID {
-		game.Player1 = *player
-	} else {
-		game.Player2 = *player
+// checkBattleEnd moves to scoring once both hands are empty and no ability is left to resolve
+func (s *GameService) checkBattleEnd(game *models.GameState) {
+	if len(game.PendingAbilities) > 0 {
+		return
 	}
 
 	if len(game.Player1.Hand) == 0 && len(game.Player2.Hand) == 0 {
 		game.Phase = models.PhaseScoring
-		game.TheaterScores = make(map[models.TheaterType]*models.TheaterScore)
+		game.TheaterScores = ComputeTheaterScores(game)
 	}
+}
 
-	s.games.Store(gameID, game)
-	return game, nil
+// Withdraw allows the current player to withdraw from the current battle
+func (s *GameService) Withdraw(gameID, playerID string, version int) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{Type: models.ActionWithdraw, PlayerID: playerID})
 }
 
-// Withdraw allows a player to withdraw from the current battle
-func (s *GameService) Withdraw(gameID, playerID string) (*models.GameState, error) {
-	game, err := s.GetGame(gameID)
+// withdraw ends the battle in the opponent's favor. It takes the current
+// player's turn, and each battle can only be withdrawn from once.
+func (s *GameService) withdraw(game *models.GameState, playerID string) error {
+	player, opponent, err := resolvePlayers(game, playerID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if game.Phase != models.PhasePlaying {
-		return nil, errors.New("cannot withdraw in current phase")
+	if game.WithdrewPlayerID != "" {
+		return ErrAlreadyWithdrawn
 	}
 
-	game.WithdrewPlayerID = playerID
-	game.Phase = models.PhaseScoring
-
-	// Calculate VP for opponent based on withdrawal rules
-	var cardsRemaining int
-	if playerID == game.Player1.ID {
-		cardsRemaining = len(game.Player1.Hand)
-	} else {
-		cardsRemaining = len(game.Player2.Hand)
+	if game.Phase != models.PhasePlaying {
+		return fmt.Errorf("%w: cannot withdraw in current phase", ErrWrongPhase)
 	}
 
-	isFirstPlayer := playerID == game.FirstPlayerID
-	vpAwarded := s.calculateWithdrawalVP(isFirstPlayer, cardsRemaining)
-
-	// Award VP to opponent
-	if playerID == game.Player1.ID {
-		game.Player2.Score += vpAwarded
-	} else {
-		game.Player1.Score += vpAwarded
+	if game.CurrentPlayerID != playerID {
+		return ErrNotYourTurn
 	}
 
-	// Check for game over
-	if game.Player1.Score >= 12 || game.Player2.Score >= 12 {
-		game.Phase = models.PhaseGameOver
+	if len(game.PendingAbilities) > 0 {
+		return ErrAbilityPending
 	}
 
-	s.games.Store(gameID, game)
-	return game, nil
+	s.concede(game, player, opponent)
+	return nil
+}
+
+// concede withdraws a player from the battle without checking whose turn
+// it is, and awards the opponent VP by the withdrawal rules
+func (s *GameService) concede(game *models.GameState, player, opponent *models.Player) {
+	game.WithdrewPlayerID = player.ID
+	game.Phase = models.PhaseScoring
+
+	cardsRemaining := len(player.Hand)
+	isFirstPlayer := player.ID == game.FirstPlayerID
+	awardBattle(game, models.BattleResult{
+		WinnerID:         opponent.ID,
+		VP:               s.calculateWithdrawalVP(isFirstPlayer, cardsRemaining),
+		WithdrewPlayerID: player.ID,
+		CardsRemaining:   cardsRemaining,
+	})
 }
 
 // calculateWithdrawalVP calculates VP awarded when a player withdraws
@@ -506,86 +582,6 @@ func (s *GameService) calculateWithdrawalVP(isFirstPlayer bool, cardsRemaining i
 	}
 }
 
-// UpdateTheaterScores updates the theater scores submitted by players
-func (s *GameService) UpdateTheaterScores(gameID, playerID string, scores map[models.TheaterType]int) (*models.GameState, error) {
-	game, err := s.GetGame(gameID)
-	if err != nil {
-		return nil, err
-	}
-
-	if game.Phase != models.PhaseScoring {
-		return nil, errors.New("game is not in scoring phase")
-	}
-
-	// If someone withdrew, we don't need manual scoring
-	if game.WithdrewPlayerID != "" {
-		return game, nil
-	}
-
-	// Initialize theater scores if needed
-	if game.TheaterScores == nil {
-		game.TheaterScores = make(map[models.TheaterType]*models.TheaterScore)
-	}
-
-	// Update scores for each theater
-	for theater, score := range scores {
-		if game.TheaterScores[theater] == nil {
-			game.TheaterScores[theater] = &models.TheaterScore{}
-		}
-
-		if playerID == game.Player1.ID {
-			game.TheaterScores[theater].Player1Total = score
-		} else {
-			game.TheaterScores[theater].Player2Total = score
-		}
-	}
-
-	// Check if both players have submitted scores
-	allScored := true
-	for _, theater := range []models.TheaterType{models.Air, models.Land, models.Sea} {
-		if game.TheaterScores[theater] == nil ||
-			game.TheaterScores[theater].Player1Total == 0 && game.TheaterScores[theater].Player2Total == 0 {
-			allScored = false
-			break
-		}
-	}
-
-	// If both players scored, calculate winner
-	if allScored {
-		s.calculateBattleWinner(game)
-	}
-
-	s.games.Store(gameID, game)
-	return game, nil
-}
-
-// calculateBattleWinner determines the winner of a battle and awards VP
-func (s *GameService) calculateBattleWinner(game *models.GameState) {
-	player1Wins := 0
-	player2Wins := 0
-
-	for _, theater := range []models.TheaterType{models.Air, models.Land, models.Sea} {
-		score := game.TheaterScores[theater]
-		if score.Player1Total > score.Player2Total {
-			player1Wins++
-		} else if score.Player2Total > score.Player1Total {
-			player2Wins++
-		}
-	}
-
-	// Award 6 VP to winner
-	if player1Wins >= 2 {
-		game.Player1.Score += 6
-	} else if player2Wins >= 2 {
-		game.Player2.Score += 6
-	}
-
-	// Check for game over
-	if game.Player1.Score >= 12 || game.Player2.Score >= 12 {
-		game.Phase = models.PhaseGameOver
-	}
-}
-
 func rotateTheaterOrder(order []models.TheaterType) []models.TheaterType {
 	if len(order) == 0 {
 		return []models.TheaterType{models.Air, models.Land, models.Sea}
@@ -597,7 +593,7 @@ func rotateTheaterOrder(order []models.TheaterType) []models.TheaterType {
 	return rotated
 }
 
-func (s *GameService) setupNextBattle(game *models.GameState) {
+func (s *GameService) setupNextBattle(game *models.GameState, deal []models.Card) {
 	if len(game.TheaterOrder) == 0 {
 		game.TheaterOrder = []models.TheaterType{models.Air, models.Land, models.Sea}
 	}
@@ -610,10 +606,7 @@ func (s *GameService) setupNextBattle(game *models.GameState) {
 		game.FirstPlayerID = game.Player1.ID
 	}
 
-	deck := s.shuffleDeck(models.AllCards())
-	game.Player1.Hand = deck[:6]
-	game.Player2.Hand = deck[6:12]
-	game.Deck = deck[12:]
+	dealHands(game, deal)
 
 	game.Theaters = map[models.TheaterType]*models.Theater{
 		models.Air:  {Type: models.Air, Cards: []models.PlayedCard{}},
@@ -627,46 +620,80 @@ func (s *GameService) setupNextBattle(game *models.GameState) {
 	game.BattleNumber++
 	game.WithdrewPlayerID = ""
 	game.TheaterScores = nil
+	game.ScoreSubmissions = nil
+	game.DisputedTheaters = nil
+	game.BattleWinnerID = ""
+	resetAbilityState(game)
 }
 
-// StartNextBattle sets up the next battle
-func (s *GameService) StartNextBattle(gameID string) (*models.GameState, error) {
-	game, err := s.GetGame(gameID)
-	if err != nil {
-		return nil, err
-	}
+// StartNextBattle sets up the next battle. Either player may start it.
+func (s *GameService) StartNextBattle(gameID, playerID string, version int) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{Type: models.ActionNextBattle, PlayerID: playerID})
+}
 
+// startNextBattle deals the next battle of the game
+func (s *GameService) startNextBattle(game *models.GameState, playerID string, deal []models.Card) error {
+	if _, _, err := resolvePlayers(game, playerID); err != nil {
+		return err
+	}
 	if game.Phase == models.PhaseGameOver {
-		return nil, errors.New("game is over")
+		return fmt.Errorf("%w: game is over", ErrWrongPhase)
 	}
 
-	if game.Phase != models.PhaseScoring {
-		return nil, errors.New("battle is not finished")
+	if game.Phase != models.PhaseScoring || game.BattleWinnerID == "" {
+		return fmt.Errorf("%w: battle is not finished", ErrWrongPhase)
 	}
 
-	s.setupNextBattle(game)
+	s.setupNextBattle(game, deal)
 
-	s.games.Store(gameID, game)
-	return game, nil
+	return nil
 }
 
-// StartNextGame resets the game after it has ended
-func (s *GameService) StartNextGame(gameID string) (*models.GameState, error) {
-	game, err := s.GetGame(gameID)
-	if err != nil {
-		return nil, err
-	}
+// StartNextGame resets the game after it has ended. Once the series is
+// decided, the next game starts a new series of the same length.
+func (s *GameService) StartNextGame(gameID, playerID string, version int) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{Type: models.ActionNextGame, PlayerID: playerID})
+}
 
+// startNextGame resets scores and deals a new game
+func (s *GameService) startNextGame(game *models.GameState, playerID string, deal []models.Card) error {
+	if _, _, err := resolvePlayers(game, playerID); err != nil {
+		return err
+	}
 	if game.Phase != models.PhaseGameOver {
-		return nil, errors.New("game is not over")
+		return fmt.Errorf("%w: game is not over", ErrWrongPhase)
+	}
+	if game.LeftPlayerID != "" {
+		return fmt.Errorf("%w: a player has left the room", ErrRoomClosed)
+	}
+
+	// A decided series is kept in the room's history and followed by a new
+	// one of the same length
+	switch {
+	case game.Series == nil:
+		game.Series = newSeries(1)
+	case game.Series.WinnerID != "":
+		game.SeriesHistory = append(game.SeriesHistory, *game.Series)
+		game.Series = newSeries(game.Series.BestOf)
 	}
 
 	// Reset scores and theater order
 	game.Player1.Score = 0
 	game.Player2.Score = 0
+	game.BattleResults = nil
 	game.TheaterOrder = []models.TheaterType{models.Air, models.Land, models.Sea}
 	game.TheaterScores = nil
+	game.ScoreSubmissions = nil
+	game.DisputedTheaters = nil
+	game.BattleWinnerID = ""
 	game.WithdrewPlayerID = ""
+	game.WinnerID = ""
+	game.ForfeitedPlayerID = ""
+
+	// Both players get a full bank for the new game
+	if game.TimeControl != nil {
+		game.Clock = newClock(game)
+	}
 
 	// Alternate first player for the new game
 	if game.FirstPlayerID == game.Player1.ID {
@@ -675,10 +702,7 @@ func (s *GameService) StartNextGame(gameID string) (*models.GameState, error) {
 		game.FirstPlayerID = game.Player1.ID
 	}
 
-	deck := s.shuffleDeck(models.AllCards())
-	game.Player1.Hand = deck[:6]
-	game.Player2.Hand = deck[6:12]
-	game.Deck = deck[12:]
+	dealHands(game, deal)
 
 	game.Theaters = map[models.TheaterType]*models.Theater{
 		models.Air:  {Type: models.Air, Cards: []models.PlayedCard{}},
@@ -690,7 +714,7 @@ func (s *GameService) StartNextGame(gameID string) (*models.GameState, error) {
 	game.CurrentPlayerID = game.FirstPlayerID
 	game.Phase = models.PhasePlaying
 	game.BattleNumber = 1
+	resetAbilityState(game)
 
-	s.games.Store(gameID, game)
-	return game, nil
+	return nil
 }
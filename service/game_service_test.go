@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestBotSeatIsKeptForTheBot(t *testing.T) {
+	s := NewSeededGameService(NewMemoryStore(), 1)
+	botRoom, err := s.CreateRoom("Alice", models.RoomOptions{Opponent: models.OpponentBot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	humanRoom, err := s.CreateRoom("Bob", models.RoomOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := s.JoinRoom(botRoom.ID, "Mallory"); !errors.Is(err, ErrRoomUnavailable) {
+		t.Errorf("human joining a bot room: err = %v, want ErrRoomUnavailable", err)
+	}
+	if _, _, err := s.SeatBot(humanRoom.ID, "Bot"); !errors.Is(err, ErrRoomUnavailable) {
+		t.Errorf("bot joining a human room: err = %v, want ErrRoomUnavailable", err)
+	}
+
+	room, game, err := s.SeatBot(botRoom.ID, "Bot")
+	if err != nil {
+		t.Fatalf("SeatBot: %v", err)
+	}
+	if room.Status != models.RoomStatusPlaying || room.Player2.Name != "Bot" || game.Player2.ID != room.Player2.ID {
+		t.Errorf("bot room after SeatBot = %+v, want the bot playing in the second seat", room)
+	}
+}
+
+// currentAndOpponent returns the IDs of the player whose turn it is and of
+// their opponent
+func currentAndOpponent(game *models.GameState) (string, string) {
+	if game.CurrentPlayerID == game.Player1.ID {
+		return game.Player1.ID, game.Player2.ID
+	}
+	return game.Player2.ID, game.Player1.ID
+}
+
+func TestWithdrawChecksPlayer(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{})
+	_, opponentID := currentAndOpponent(game)
+
+	if _, err := s.Withdraw(game.ID, opponentID, 0); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("withdrawing out of turn = %v, want ErrNotYourTurn", err)
+	}
+	for _, playerID := range []string{"", "stranger"} {
+		if _, err := s.Withdraw(game.ID, playerID, 0); !errors.Is(err, ErrNotInGame) {
+			t.Errorf("Withdraw(%q) = %v, want ErrNotInGame", playerID, err)
+		}
+	}
+
+	after, err := s.GetGame(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Version != game.Version || after.WithdrewPlayerID != "" {
+		t.Errorf("rejected withdrawals changed the game from version %d to %d", game.Version, after.Version)
+	}
+}
+
+func TestWithdrawOncePerBattle(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{})
+	currentID, opponentID := currentAndOpponent(game)
+
+	withdrawn, err := s.Withdraw(game.ID, currentID, 0)
+	if err != nil {
+		t.Fatalf("Withdraw: %v", err)
+	}
+	for _, playerID := range []string{currentID, opponentID} {
+		if _, err := s.Withdraw(game.ID, playerID, 0); !errors.Is(err, ErrAlreadyWithdrawn) {
+			t.Errorf("second withdrawal by %s = %v, want ErrAlreadyWithdrawn", playerID, err)
+		}
+	}
+
+	after, err := s.GetGame(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Version != withdrawn.Version || len(after.BattleResults) != 1 {
+		t.Errorf("second withdrawal changed the game: version %d to %d, %d results",
+			withdrawn.Version, after.Version, len(after.BattleResults))
+	}
+}
+
+func TestWithdrawalVPIsRecorded(t *testing.T) {
+	tests := []struct {
+		playerID string // p1 plays first
+		hand     []int
+		vp       int
+	}{
+		{"p1", []int{1, 2, 3, 4}, 2},
+		{"p1", []int{1, 2}, 3},
+		{"p1", []int{1}, 4},
+		{"p1", nil, 6},
+		{"p2", []int{1, 2, 3, 4, 5}, 2},
+		{"p2", []int{1, 2, 3}, 3},
+		{"p2", []int{1, 2}, 4},
+		{"p2", []int{1}, 6},
+	}
+
+	for _, tt := range tests {
+		game := newBoard(nil, nil)
+		player, winner := &game.Player1, &game.Player2
+		if tt.playerID == "p2" {
+			player, winner = &game.Player2, &game.Player1
+		}
+		player.Hand = testCards(tt.hand...)
+		game.CurrentPlayerID = tt.playerID
+
+		mustApply(t, game, models.Action{Type: models.ActionWithdraw, PlayerID: tt.playerID})
+		want := models.BattleResult{
+			BattleNumber:     1,
+			FirstPlayerID:    "p1",
+			WinnerID:         winner.ID,
+			VP:               tt.vp,
+			WithdrewPlayerID: tt.playerID,
+			CardsRemaining:   len(tt.hand),
+		}
+		if len(game.BattleResults) != 1 {
+			t.Fatalf("%s with %d cards: %d battle results, want 1", tt.playerID, len(tt.hand), len(game.BattleResults))
+		}
+		got := game.BattleResults[0]
+		got.TheaterScores, got.Board = nil, nil
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s with %d cards: result = %+v, want %+v", tt.playerID, len(tt.hand), got, want)
+		}
+		if winner.Score != tt.vp || player.Score != 0 {
+			t.Errorf("%s with %d cards: scores %d-%d, want %d for the opponent", tt.playerID, len(tt.hand), player.Score, winner.Score, tt.vp)
+		}
+	}
+}
+
+func TestStrictTurnPassesAfterOneAction(t *testing.T) {
+	game := newBoard([]int{1, 7, 13}, []int{2, 8, 14})
+
+	mustApply(t, game, playAction("p1", 1, models.Land, false))
+	if game.CurrentPlayerID != "p2" {
+		t.Fatalf("current player after one card = %s, want p2", game.CurrentPlayerID)
+	}
+	if err := ApplyAction(game, playAction("p1", 7, models.Land, true)); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("second card in the same turn = %v, want ErrNotYourTurn", err)
+	}
+	if err := ApplyAction(game, models.Action{Type: models.ActionEndTurn, PlayerID: "p2"}); !errors.Is(err, ErrWrongMode) {
+		t.Errorf("ending the turn by hand = %v, want ErrWrongMode", err)
+	}
+
+	// An ability is part of the action that triggered it
+	mustApply(t, game, playAction("p2", 8, models.Land, true))
+	if game.CurrentPlayerID != "p2" {
+		t.Fatalf("current player with Ambush pending = %s, want p2", game.CurrentPlayerID)
+	}
+	mustApply(t, game, resolveAction("p2", models.AbilityChoice{Theater: models.Land, OwnerID: "p1"}))
+	if game.CurrentPlayerID != "p1" {
+		t.Errorf("current player after Ambush resolved = %s, want p1", game.CurrentPlayerID)
+	}
+}
+
+func TestStrictTurnSkipsPlayerWithoutCards(t *testing.T) {
+	game := newBoard([]int{1, 7, 13}, []int{2})
+
+	mustApply(t, game,
+		playAction("p1", 1, models.Air, false),
+		playAction("p2", 2, models.Air, false),
+		playAction("p1", 7, models.Land, false),
+	)
+	if game.CurrentPlayerID != "p1" {
+		t.Fatalf("current player with p2 out of cards = %s, want p1", game.CurrentPlayerID)
+	}
+	mustApply(t, game, playAction("p1", 13, models.Sea, false))
+	if game.Phase != models.PhaseScoring {
+		t.Errorf("phase with both hands empty = %s, want %s", game.Phase, models.PhaseScoring)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import "github.com/dfturn/alns/models"
+
+// Helpers for agents that search over games without going through a
+// GameService, such as bots and the simulator. They work on detached game
+// states and never touch a store.
+
+// ActingPlayerID returns the player who must act next: the player resolving
+// the top pending ability, or else the current player. It is empty when
+// the game is not being played.
+func ActingPlayerID(game *models.GameState) string {
+	if game.Phase != models.PhasePlaying {
+		return ""
+	}
+	if len(game.PendingAbilities) > 0 {
+		return game.PendingAbilities[len(game.PendingAbilities)-1].PlayerID
+	}
+	return game.CurrentPlayerID
+}
+
+// LegalActions lists every action playerID may take in the playing phase
+func LegalActions(game *models.GameState, playerID string) []models.Action {
+	if playerID == "" || ActingPlayerID(game) != playerID {
+		return nil
+	}
+
+	if len(game.PendingAbilities) > 0 {
+		return abilityActions(game, game.PendingAbilities[len(game.PendingAbilities)-1])
+	}
+
+	var hand []models.Card
+	switch playerID {
+	case game.Player1.ID:
+		hand = game.Player1.Hand
+	case game.Player2.ID:
+		hand = game.Player2.Hand
+	default:
+		return nil
+	}
+
+	var actions []models.Action
+	for _, card := range hand {
+		for _, t := range game.TheaterOrder {
+			if _, err := checkPlacement(game, playerID, card, t, true); err == nil {
+				actions = append(actions, models.Action{Type: models.ActionPlayCard, PlayerID: playerID, CardID: card.ID, Theater: t, FaceUp: true})
+			}
+			actions = append(actions, models.Action{Type: models.ActionPlayCard, PlayerID: playerID, CardID: card.ID, Theater: t})
+		}
+	}
+	if game.Mode == models.ModeSandbox {
+		actions = append(actions, models.Action{Type: models.ActionEndTurn, PlayerID: playerID})
+	}
+	actions = append(actions, models.Action{Type: models.ActionWithdraw, PlayerID: playerID})
+	return actions
+}
+
+// abilityActions lists the choices that resolve a pending ability. Hidden
+// face-down targets are named by theater and owner rather than card ID, so
+// the choices are valid for a player who cannot see those cards.
+func abilityActions(game *models.GameState, pending models.PendingAbility) []models.Action {
+	var choices []models.AbilityChoice
+	target := func(bc boardCard) models.AbilityChoice {
+		if !bc.Played.FaceUp && bc.Played.PlayerID != pending.PlayerID {
+			return models.AbilityChoice{Theater: bc.Theater, OwnerID: bc.Played.PlayerID}
+		}
+		return models.AbilityChoice{CardID: bc.Played.Card.ID}
+	}
+
+	switch pending.Ability {
+	case models.AbilityAmbush:
+		for _, bc := range uncoveredCards(game, game.TheaterOrder, "") {
+			choices = append(choices, target(bc))
+		}
+	case models.AbilityManeuver:
+		for _, bc := range uncoveredCards(game, adjacentTheaters(game, pending.Theater), "") {
+			choices = append(choices, target(bc))
+		}
+	case models.AbilityDisrupt:
+		for _, bc := range uncoveredCards(game, game.TheaterOrder, pending.PlayerID) {
+			choices = append(choices, target(bc))
+		}
+	case models.AbilityTransport:
+		for _, from := range game.TheaterOrder {
+			for _, pc := range game.Theaters[from].Cards {
+				if pc.PlayerID != pending.OwnerID {
+					continue
+				}
+				for _, to := range game.TheaterOrder {
+					if to != from {
+						choices = append(choices, models.AbilityChoice{CardID: pc.Card.ID, ToTheater: to})
+					}
+				}
+			}
+		}
+	case models.AbilityRedeploy:
+		for _, t := range game.TheaterOrder {
+			for _, pc := range game.Theaters[t].Cards {
+				if pc.PlayerID == pending.OwnerID && !pc.FaceUp {
+					choices = append(choices, models.AbilityChoice{CardID: pc.Card.ID})
+				}
+			}
+		}
+	case models.AbilityReinforce:
+		for _, t := range adjacentTheaters(game, pending.Theater) {
+			choices = append(choices, models.AbilityChoice{ToTheater: t})
+		}
+	}
+	if pending.Optional {
+		choices = append(choices, models.AbilityChoice{Skip: true})
+	}
+
+	actions := make([]models.Action, len(choices))
+	for i := range choices {
+		actions[i] = models.Action{Type: models.ActionResolveAbility, PlayerID: pending.PlayerID, Choice: &choices[i]}
+	}
+	return actions
+}
+
+// ApplyAction carries out an action on a detached game state, enforcing
+// the same rules as the GameService methods
+func ApplyAction(game *models.GameState, action models.Action) error {
+	var rules GameService
+	return rules.applyAction(game, action)
+}
+
+// CloneGame returns a deep copy of a game state
+func CloneGame(game *models.GameState) *models.GameState {
+	return cloneGame(game)
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/dfturn/alns/models"
+)
+
+// Expiry sets how long rooms and games are kept after their last activity
+type Expiry struct {
+	WaitingRoom  time.Duration // Rooms nobody joined, and rooms closed before a game started
+	FinishedGame time.Duration // Games that are over or whose room was closed or abandoned
+	IdleGame     time.Duration // Games still in progress
+}
+
+// DefaultExpiry is used for any Expiry field that is zero
+var DefaultExpiry = Expiry{
+	WaitingRoom:  time.Hour,
+	FinishedGame: 24 * time.Hour,
+	IdleGame:     7 * 24 * time.Hour,
+}
+
+// LeaveRoom takes a player out of a room. Leaving a room nobody has joined
+// closes it. Leaving a game in progress ends it in the other player's
+// favor and marks the room abandoned; leaving a game that is over closes
+// the room. Either way the other player is notified through the game's
+// event stream. Spectators who leave just stop watching.
+func (s *GameService) LeaveRoom(roomID, playerID string) (*models.Room, error) {
+	return s.leaveRoom(roomID, playerID, false)
+}
+
+// CloseRoom lets the player who created a room close it for both players.
+// A game in progress counts as left by them.
+func (s *GameService) CloseRoom(roomID, playerID string) (*models.Room, error) {
+	return s.leaveRoom(roomID, playerID, true)
+}
+
+func (s *GameService) leaveRoom(roomID, playerID string, closing bool) (*models.Room, error) {
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+
+	stored, err := s.loadRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	room := cloneRoom(stored)
+	if IsSpectator(room, playerID) && !closing {
+		// Spectators just stop watching
+		delete(room.Spectators, playerID)
+		if err := s.store.SaveRoom(room); err != nil {
+			return nil, err
+		}
+		return cloneRoom(room), nil
+	}
+	if !inRoom(room, playerID) {
+		return nil, ErrNotInRoom
+	}
+	if closing && room.Player1.ID != playerID {
+		return nil, ErrNotRoomCreator
+	}
+	if room.Status == models.RoomStatusClosed || room.Status == models.RoomStatusAbandoned {
+		return nil, ErrRoomClosed
+	}
+
+	room.Status = models.RoomStatusClosed
+	room.LeftPlayerID = playerID
+	room.UpdatedAt = time.Now()
+	if room.GameID == "" {
+		if err := s.store.SaveRoom(room); err != nil {
+			return nil, err
+		}
+		return cloneRoom(room), nil
+	}
+
+	game, err := s.GetGame(room.GameID)
+	if err != nil {
+		return nil, err
+	}
+	if game.Phase != models.PhaseGameOver && !closing {
+		room.Status = models.RoomStatusAbandoned
+	}
+	if _, err := s.actInRoom(room, room.GameID, 0, models.Action{Type: models.ActionLeave, PlayerID: playerID}); err != nil {
+		return nil, err
+	}
+	return cloneRoom(room), nil
+}
+
+// inRoom reports whether playerID holds a seat in a room
+func inRoom(room *models.Room, playerID string) bool {
+	return (room.Player1 != nil && room.Player1.ID == playerID) ||
+		(room.Player2 != nil && room.Player2.ID == playerID)
+}
+
+// leave ends a game for a player who left its room. Nothing can be played
+// after that, not even another game.
+func (s *GameService) leave(game *models.GameState, playerID string) error {
+	_, opponent, err := resolvePlayers(game, playerID)
+	if err != nil {
+		return err
+	}
+	if game.LeftPlayerID != "" {
+		return errNoChange
+	}
+
+	game.LeftPlayerID = playerID
+	if game.Phase != models.PhaseGameOver {
+		game.Phase = models.PhaseGameOver
+		game.WinnerID = opponent.ID
+		game.PendingAbilities = nil
+	}
+	return nil
+}
+
+// StartJanitor sweeps out expired rooms and games every interval until
+// the returned function is called
+func (s *GameService) StartJanitor(expiry Expiry, interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				removed, err := s.Sweep(now, expiry)
+				if err != nil {
+					log.Printf("Failed to sweep rooms: %v", err)
+				}
+				if removed > 0 {
+					log.Printf("Removed %d expired rooms", removed)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// Sweep deletes the rooms, along with their games and move logs, that have
+// seen no activity for longer than expiry allows as of now. It returns the
+// number of rooms deleted. Subscribers to a deleted game have their
+// streams closed.
+func (s *GameService) Sweep(now time.Time, expiry Expiry) (int, error) {
+	if expiry.WaitingRoom == 0 {
+		expiry.WaitingRoom = DefaultExpiry.WaitingRoom
+	}
+	if expiry.FinishedGame == 0 {
+		expiry.FinishedGame = DefaultExpiry.FinishedGame
+	}
+	if expiry.IdleGame == 0 {
+		expiry.IdleGame = DefaultExpiry.IdleGame
+	}
+
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+
+	rooms, err := s.store.ListRooms()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, room := range rooms {
+		expired, err := s.roomExpired(room, now, expiry)
+		if err != nil {
+			return removed, err
+		}
+		if !expired {
+			continue
+		}
+
+		if room.GameID != "" {
+			if err := s.deleteGame(room.GameID); err != nil {
+				return removed, err
+			}
+		}
+		if err := s.store.DeleteRoom(room.ID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// roomExpired reports whether a room has outlived its expiry. Callers must
+// hold roomsMu.
+func (s *GameService) roomExpired(room *models.Room, now time.Time, expiry Expiry) (bool, error) {
+	if room.GameID == "" {
+		return now.Sub(room.UpdatedAt) > expiry.WaitingRoom, nil
+	}
+
+	lock := s.gameLock(room.GameID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	game, err := s.store.GetGame(room.GameID)
+	if errors.Is(err, ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// A game's last activity is its last move, or a later change to the room
+	lastActive := room.UpdatedAt
+	events, err := s.store.ListEvents(game.ID)
+	if err != nil {
+		return false, err
+	}
+	if n := len(events); n > 0 && events[n-1].Timestamp.After(lastActive) {
+		lastActive = events[n-1].Timestamp
+	}
+
+	ttl := expiry.IdleGame
+	if game.Phase == models.PhaseGameOver || room.Status == models.RoomStatusClosed || room.Status == models.RoomStatusAbandoned {
+		ttl = expiry.FinishedGame
+	}
+	return now.Sub(lastActive) > ttl, nil
+}
+
+// deleteGame removes a game and its move log, stops its clock and ends
+// the streams of its subscribers
+func (s *GameService) deleteGame(gameID string) error {
+	lock := s.gameLock(gameID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := s.store.DeleteGame(gameID); err != nil {
+		return err
+	}
+	if timer, ok := s.timers.LoadAndDelete(gameID); ok {
+		timer.(*time.Timer).Stop()
+	}
+	s.events.close(gameID)
+	s.locks.Delete(gameID)
+	return nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestSweepExpiresEachKindOfRoom(t *testing.T) {
+	s := NewSeededGameService(NewMemoryStore(), 1)
+	expiry := Expiry{WaitingRoom: time.Hour, FinishedGame: 2 * time.Hour, IdleGame: 3 * time.Hour}
+
+	waiting, err := s.CreateRoom("Alice", models.RoomOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	startRoom := func() (*models.Room, *models.GameState) {
+		room, err := s.CreateRoom("Alice", models.RoomOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		room, game, err := s.JoinRoom(room.ID, "Bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return room, game
+	}
+	idle, _ := startRoom()
+	finished, finishedGame := startRoom()
+	if _, err := s.LeaveRoom(finished.ID, finished.Player1.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	steps := []struct {
+		after time.Duration
+		gone  []*models.Room
+		kept  []*models.Room
+	}{
+		{30 * time.Minute, nil, []*models.Room{waiting, finished, idle}},
+		{90 * time.Minute, []*models.Room{waiting}, []*models.Room{finished, idle}},
+		{150 * time.Minute, []*models.Room{finished}, []*models.Room{idle}},
+		{210 * time.Minute, []*models.Room{idle}, nil},
+	}
+	for _, step := range steps {
+		removed, err := s.Sweep(now.Add(step.after), expiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if removed != len(step.gone) {
+			t.Errorf("sweep after %s removed %d rooms, want %d", step.after, removed, len(step.gone))
+		}
+		for _, room := range step.gone {
+			if _, err := s.GetRoom(room.ID); !errors.Is(err, ErrRoomNotFound) {
+				t.Errorf("room %s survived the sweep after %s", room.ID, step.after)
+			}
+		}
+		for _, room := range step.kept {
+			if _, err := s.GetRoom(room.ID); err != nil {
+				t.Errorf("room %s was swept after %s: %v", room.ID, step.after, err)
+			}
+		}
+	}
+
+	if _, err := s.store.GetGame(finishedGame.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("swept game is still stored: %v", err)
+	}
+	if events, _ := s.store.ListEvents(finishedGame.ID); len(events) != 0 {
+		t.Errorf("swept game left %d events in its move log", len(events))
+	}
+}
+
+func TestLeavingMidGameAbandonsRoom(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{})
+
+	room, err := s.LeaveRoom(game.RoomID, game.Player1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if room.Status != models.RoomStatusAbandoned || room.LeftPlayerID != game.Player1.ID {
+		t.Errorf("room after leaving = %s left by %q, want abandoned by %q", room.Status, room.LeftPlayerID, game.Player1.ID)
+	}
+
+	after, err := s.GetGame(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Phase != models.PhaseGameOver || after.WinnerID != game.Player2.ID || after.LeftPlayerID != game.Player1.ID {
+		t.Errorf("game after leaving: phase %s won by %q, want it over and won by %q", after.Phase, after.WinnerID, game.Player2.ID)
+	}
+
+	if _, err := s.LeaveRoom(game.RoomID, game.Player2.ID); !errors.Is(err, ErrRoomClosed) {
+		t.Errorf("leaving an abandoned room: err = %v, want ErrRoomClosed", err)
+	}
+}
+
+func TestOnlyCreatorClosesRoom(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{})
+
+	if _, err := s.CloseRoom(game.RoomID, game.Player2.ID); !errors.Is(err, ErrNotRoomCreator) {
+		t.Errorf("closing as the second player: err = %v, want ErrNotRoomCreator", err)
+	}
+	room, err := s.CloseRoom(game.RoomID, game.Player1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if room.Status != models.RoomStatusClosed {
+		t.Errorf("room closed by its creator is %s, want closed", room.Status)
+	}
+}
+
+func TestJanitorSweepsUntilStopped(t *testing.T) {
+	s := NewSeededGameService(NewMemoryStore(), 1)
+	room, err := s.CreateRoom("Alice", models.RoomOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := s.StartJanitor(Expiry{WaitingRoom: time.Millisecond}, 5*time.Millisecond)
+	defer stop()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := s.GetRoom(room.ID); errors.Is(err, ErrRoomNotFound) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("janitor did not sweep out the expired room")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/dfturn/alns/models"
+)
+
+// errNoChange lets an update succeed without saving a new version
+var errNoChange = errors.New("no change")
+
+// gameLock returns the mutex serializing all access to a game
+func (s *GameService) gameLock(gameID string) *sync.Mutex {
+	lock, _ := s.locks.LoadOrStore(gameID, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// loadGame returns the live game state. Callers must hold the game lock.
+func (s *GameService) loadGame(gameID string) (*models.GameState, error) {
+	game, err := s.store.GetGame(gameID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrGameNotFound
+	}
+	return game, err
+}
+
+// randIntn is a goroutine-safe rand.Intn
+func (s *GameService) randIntn(n int) int {
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+	return s.rand.Intn(n)
+}
+
+// randInt63 is a goroutine-safe rand.Int63
+func (s *GameService) randInt63() int64 {
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+	return s.rand.Int63()
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dfturn/alns/models"
+	"github.com/google/uuid"
+)
+
+// ticketTimeout is how long a waiting ticket stays in the queue without
+// its player checking on it. Players poll far more often than this, so a
+// quiet ticket means they have gone.
+const ticketTimeout = 30 * time.Second
+
+// matchQueue holds quick-match tickets in the order they were created
+type matchQueue struct {
+	mu      sync.Mutex
+	tickets map[string]*models.MatchTicket
+	order   []string // IDs of waiting tickets, oldest first
+}
+
+// ListLobby returns the rooms waiting for a second player that are not
+// private, longest waiting first
+func (s *GameService) ListLobby() ([]models.LobbyRoom, error) {
+	rooms, err := s.ListRooms()
+	if err != nil {
+		return nil, err
+	}
+
+	lobby := []models.LobbyRoom{}
+	for _, room := range rooms {
+		if room.Status != models.RoomStatusWaiting || room.Options.Private {
+			continue
+		}
+		lobby = append(lobby, models.LobbyRoom{
+			ID:              room.ID,
+			HostName:        room.Player1.Name,
+			Mode:            room.Options.Mode,
+			TimeControl:     room.Options.TimeControl,
+			BestOf:          room.Options.BestOf,
+			AllowSpectators: room.Options.AllowSpectators,
+			SpectatorDelay:  room.Options.SpectatorDelay,
+			SpectatorCount:  len(room.Spectators),
+			WaitingSince:    room.UpdatedAt,
+		})
+	}
+
+	sort.Slice(lobby, func(i, j int) bool {
+		if !lobby[i].WaitingSince.Equal(lobby[j].WaitingSince) {
+			return lobby[i].WaitingSince.Before(lobby[j].WaitingSince)
+		}
+		return lobby[i].ID < lobby[j].ID
+	})
+	return lobby, nil
+}
+
+// QuickMatch puts a player in the quick-match queue. If another player is
+// waiting for the same mode, series length and time control, a private
+// room is created for them with CreateRoom, this player joins it with
+// JoinRoom, and the returned ticket is already matched. Otherwise the
+// ticket waits until another player comes along; the player checks it
+// with GetMatchTicket.
+func (s *GameService) QuickMatch(playerName string, options models.RoomOptions) (*models.MatchTicket, error) {
+	if options.Opponent != "" && options.Opponent != models.OpponentHuman {
+		return nil, fmt.Errorf("%w: quick match only pairs people", ErrInvalidOptions)
+	}
+	if options.Seed != nil {
+		return nil, fmt.Errorf("%w: quick match rooms cannot be seeded", ErrInvalidOptions)
+	}
+	// Tickets must hold options CreateRoom accepts, or they would fail
+	// whoever they are paired with
+	if err := validateRoomOptions(&options); err != nil {
+		return nil, err
+	}
+	options.Private = true
+
+	s.queue.mu.Lock()
+	defer s.queue.mu.Unlock()
+
+	now := time.Now()
+	ticket := &models.MatchTicket{
+		ID:         uuid.New().String(),
+		PlayerName: playerName,
+		Options:    options,
+		Status:     models.MatchWaiting,
+		LastSeen:   now,
+	}
+
+	s.dropStaleTickets(now)
+	for i, id := range s.queue.order {
+		other := s.queue.tickets[id]
+		if !sameMatchOptions(other.Options, options) {
+			continue
+		}
+
+		room, err := s.CreateRoom(other.PlayerName, other.Options)
+		if err != nil {
+			return nil, err
+		}
+		room, _, err = s.JoinRoom(room.ID, playerName)
+		if err != nil {
+			return nil, err
+		}
+
+		s.queue.order = append(s.queue.order[:i], s.queue.order[i+1:]...)
+		other.Status, other.RoomID, other.PlayerID = models.MatchFound, room.ID, room.Player1.ID
+		ticket.Status, ticket.RoomID, ticket.PlayerID = models.MatchFound, room.ID, room.Player2.ID
+		s.queue.tickets[ticket.ID] = ticket
+		clone := *ticket
+		return &clone, nil
+	}
+
+	if s.queue.tickets == nil {
+		s.queue.tickets = make(map[string]*models.MatchTicket)
+	}
+	s.queue.tickets[ticket.ID] = ticket
+	s.queue.order = append(s.queue.order, ticket.ID)
+	clone := *ticket
+	return &clone, nil
+}
+
+// GetMatchTicket returns a quick-match ticket. Checking a waiting ticket
+// keeps it in the queue.
+func (s *GameService) GetMatchTicket(ticketID string) (*models.MatchTicket, error) {
+	s.queue.mu.Lock()
+	defer s.queue.mu.Unlock()
+
+	s.dropStaleTickets(time.Now())
+	ticket, ok := s.queue.tickets[ticketID]
+	if !ok {
+		return nil, ErrTicketNotFound
+	}
+	ticket.LastSeen = time.Now()
+	clone := *ticket
+	return &clone, nil
+}
+
+// CancelMatch takes a waiting ticket out of the queue. Matched tickets
+// cannot be cancelled; leave the room instead.
+func (s *GameService) CancelMatch(ticketID string) error {
+	s.queue.mu.Lock()
+	defer s.queue.mu.Unlock()
+
+	ticket, ok := s.queue.tickets[ticketID]
+	if !ok {
+		return ErrTicketNotFound
+	}
+	if ticket.Status != models.MatchWaiting {
+		return ErrTicketMatched
+	}
+	s.removeTicket(ticketID)
+	return nil
+}
+
+// dropStaleTickets forgets waiting tickets whose players stopped checking
+// on them, and matched tickets nobody has picked up for a long time.
+// Callers must hold queue.mu.
+func (s *GameService) dropStaleTickets(now time.Time) {
+	for id, ticket := range s.queue.tickets {
+		timeout := ticketTimeout
+		if ticket.Status == models.MatchFound {
+			timeout = DefaultExpiry.WaitingRoom
+		}
+		if now.Sub(ticket.LastSeen) > timeout {
+			s.removeTicket(id)
+		}
+	}
+}
+
+// removeTicket deletes a ticket. Callers must hold queue.mu.
+func (s *GameService) removeTicket(ticketID string) {
+	delete(s.queue.tickets, ticketID)
+	for i, id := range s.queue.order {
+		if id == ticketID {
+			s.queue.order = append(s.queue.order[:i], s.queue.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// sameMatchOptions reports whether two players asked for the same game
+func sameMatchOptions(a, b models.RoomOptions) bool {
+	if a.Mode != b.Mode || a.BestOf != b.BestOf {
+		return false
+	}
+	if a.TimeControl == nil || b.TimeControl == nil {
+		return a.TimeControl == nil && b.TimeControl == nil
+	}
+	return *a.TimeControl == *b.TimeControl
+}
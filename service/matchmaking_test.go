@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestLobbyLeavesOutSeededAndBotRooms(t *testing.T) {
+	s := NewSeededGameService(NewMemoryStore(), 1)
+	seed := int64(42)
+	seeded, err := s.CreateRoom("Alice", models.RoomOptions{Seed: &seed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	botRoom, err := s.CreateRoom("Carol", models.RoomOptions{Opponent: models.OpponentBot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	open, err := s.CreateRoom("Bob", models.RoomOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lobby, err := s.ListLobby()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lobby) != 1 || lobby[0].ID != open.ID {
+		t.Errorf("lobby = %+v, want only room %s and not seeded room %s or bot room %s", lobby, open.ID, seeded.ID, botRoom.ID)
+	}
+}
+
+func TestQuickMatchRejectsInvalidOptions(t *testing.T) {
+	s := NewSeededGameService(NewMemoryStore(), 1)
+	invalid := []models.RoomOptions{
+		{Mode: "bogus"},
+		{Mode: models.ModeSandbox, TimeControl: &models.TimeControl{PerMoveSeconds: 30}},
+		{BestOf: 2},
+		{SpectatorDelay: -1},
+	}
+	for _, options := range invalid {
+		if _, err := s.QuickMatch("Alice", options); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("QuickMatch(%+v) = %v, want ErrInvalidOptions", options, err)
+		}
+		// The next player asking for the same options is not paired with
+		// a ticket that can never make a room
+		if _, err := s.QuickMatch("Bob", options); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("second QuickMatch(%+v) = %v, want ErrInvalidOptions", options, err)
+		}
+	}
+	if len(s.queue.order) != 0 {
+		t.Errorf("queue holds %d tickets, want none", len(s.queue.order))
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/dfturn/alns/models"
+)
+
+// aerodromeMaxStrength is the strongest card Aerodrome lets its owner
+// deploy to a non-matching theater
+const aerodromeMaxStrength = 3
+
+// checkPlacement validates a Deploy (face-up) or Improvise (face-down).
+// Face-down cards may go to any theater. Face-up cards must match the
+// theater, unless Air Drop or Aerodrome allows otherwise. It reports
+// whether Air Drop was needed, so the caller can use it up.
+func checkPlacement(game *models.GameState, playerID string, card models.Card, theater models.TheaterType, faceUp bool) (usesAirDrop bool, err error) {
+	if game.Theaters[theater] == nil {
+		return false, fmt.Errorf("%w: %q", ErrUnknownTheater, theater)
+	}
+
+	if !faceUp || card.Theater == theater {
+		return false, nil
+	}
+
+	if card.Strength <= aerodromeMaxStrength && hasActiveAbility(game, playerID, models.AbilityAerodrome) {
+		return false, nil
+	}
+
+	if game.AirDropPlayerID == playerID && game.AirDropReady {
+		return true, nil
+	}
+
+	return false, fmt.Errorf("%w: %s cannot be deployed to %s", ErrInvalidPlacement, card.Name, theater)
+}
+
+// hasActiveAbility reports whether playerID has a face-up card with the ability on the board
+func hasActiveAbility(game *models.GameState, playerID string, ability models.Ability) bool {
+	for _, bc := range activeAbilityCards(game, ability) {
+		if bc.Played.PlayerID == playerID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestCheckPlacement(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(game *models.GameState)
+		cardID     int
+		theater    models.TheaterType
+		faceUp     bool
+		want       error
+		useAirDrop bool
+	}{
+		{name: "matching theater", cardID: 12, theater: models.Land, faceUp: true},
+		{name: "wrong theater", cardID: 12, theater: models.Sea, faceUp: true, want: ErrInvalidPlacement},
+		{name: "unknown theater face-up", cardID: 12, theater: "space", faceUp: true, want: ErrUnknownTheater},
+		{name: "unknown theater face-down", cardID: 12, theater: "space", want: ErrUnknownTheater},
+		{name: "face-down in air", cardID: 12, theater: models.Air},
+		{name: "face-down in sea", cardID: 12, theater: models.Sea},
+		{
+			name:    "face-down under enemy Aerodrome",
+			setup:   func(game *models.GameState) { place(game, models.Air, "p2", 4, true) },
+			cardID:  18,
+			theater: models.Land,
+		},
+		{
+			name:    "Aerodrome allows strength 3",
+			setup:   func(game *models.GameState) { place(game, models.Air, "p1", 4, true) },
+			cardID:  9,
+			theater: models.Sea,
+			faceUp:  true,
+		},
+		{
+			name:    "Aerodrome allows strength 1",
+			setup:   func(game *models.GameState) { place(game, models.Air, "p1", 4, true) },
+			cardID:  13,
+			theater: models.Land,
+			faceUp:  true,
+		},
+		{
+			name:    "Aerodrome stops at strength 4",
+			setup:   func(game *models.GameState) { place(game, models.Air, "p1", 4, true) },
+			cardID:  10,
+			theater: models.Sea,
+			faceUp:  true,
+			want:    ErrInvalidPlacement,
+		},
+		{
+			name:    "face-down Aerodrome",
+			setup:   func(game *models.GameState) { place(game, models.Air, "p1", 4, false) },
+			cardID:  9,
+			theater: models.Sea,
+			faceUp:  true,
+			want:    ErrInvalidPlacement,
+		},
+		{
+			name:    "opponent's Aerodrome",
+			setup:   func(game *models.GameState) { place(game, models.Air, "p2", 4, true) },
+			cardID:  9,
+			theater: models.Sea,
+			faceUp:  true,
+			want:    ErrInvalidPlacement,
+		},
+		{
+			name: "Air Drop allows any strength",
+			setup: func(game *models.GameState) {
+				game.AirDropPlayerID, game.AirDropReady = "p1", true
+			},
+			cardID:     18,
+			theater:    models.Air,
+			faceUp:     true,
+			useAirDrop: true,
+		},
+		{
+			name: "Air Drop not needed in matching theater",
+			setup: func(game *models.GameState) {
+				game.AirDropPlayerID, game.AirDropReady = "p1", true
+			},
+			cardID:  18,
+			theater: models.Sea,
+			faceUp:  true,
+		},
+		{
+			name: "Air Drop not yet ready",
+			setup: func(game *models.GameState) {
+				game.AirDropPlayerID = "p1"
+			},
+			cardID:  18,
+			theater: models.Air,
+			faceUp:  true,
+			want:    ErrInvalidPlacement,
+		},
+		{
+			name: "opponent's Air Drop",
+			setup: func(game *models.GameState) {
+				game.AirDropPlayerID, game.AirDropReady = "p2", true
+			},
+			cardID:  18,
+			theater: models.Air,
+			faceUp:  true,
+			want:    ErrInvalidPlacement,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newBoard(nil, nil)
+			if tt.setup != nil {
+				tt.setup(game)
+			}
+			useAirDrop, err := checkPlacement(game, "p1", testCard(tt.cardID), tt.theater, tt.faceUp)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			if useAirDrop != tt.useAirDrop {
+				t.Errorf("uses Air Drop = %v, want %v", useAirDrop, tt.useAirDrop)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package service
+
+import "github.com/dfturn/alns/models"
+
+// resolvePlayers returns the player playerID names and their opponent, both
+// pointing into game so that changes to them are changes to the game. Every
+// action taken by a player resolves them first, so that an unknown ID is
+// rejected rather than mistaken for one of the seats.
+func resolvePlayers(game *models.GameState, playerID string) (player, opponent *models.Player, err error) {
+	switch {
+	case playerID == "":
+	case playerID == game.Player1.ID:
+		return &game.Player1, &game.Player2, nil
+	case playerID == game.Player2.ID:
+		return &game.Player2, &game.Player1, nil
+	}
+	return nil, nil, ErrNotInGame
+}
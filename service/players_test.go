@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+// newTestGame seats two players in a new room and returns the game they
+// are dealt
+func newTestGame(t *testing.T, options models.RoomOptions) (*GameService, *models.GameState) {
+	t.Helper()
+	s := NewSeededGameService(NewMemoryStore(), 1)
+	room, err := s.CreateRoom("Alice", options)
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	_, game, err := s.JoinRoom(room.ID, "Bob")
+	if err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	return s, game
+}
+
+func TestActionsRejectUnknownPlayers(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{Mode: models.ModeSandbox})
+	cardID := game.Player1.Hand[0].ID
+	if game.CurrentPlayerID != game.Player1.ID {
+		cardID = game.Player2.Hand[0].ID
+	}
+
+	actions := map[string]func(playerID string) error{
+		"PlayCard": func(playerID string) error {
+			_, err := s.PlayCard(game.ID, playerID, 0, cardID, models.Air, false)
+			return err
+		},
+		"EndTurn": func(playerID string) error {
+			_, err := s.EndTurn(game.ID, playerID, 0)
+			return err
+		},
+		"ResolveAbility": func(playerID string) error {
+			_, err := s.ResolveAbility(game.ID, playerID, 0, models.AbilityChoice{Skip: true})
+			return err
+		},
+		"Withdraw": func(playerID string) error {
+			_, err := s.Withdraw(game.ID, playerID, 0)
+			return err
+		},
+		"UpdateTheaterScores": func(playerID string) error {
+			_, err := s.UpdateTheaterScores(game.ID, playerID, 0, map[models.TheaterType]models.TheaterScore{})
+			return err
+		},
+		"AcceptServerScores": func(playerID string) error {
+			_, err := s.AcceptServerScores(game.ID, playerID, 0)
+			return err
+		},
+		"StartNextBattle": func(playerID string) error {
+			_, err := s.StartNextBattle(game.ID, playerID, 0)
+			return err
+		},
+		"StartNextGame": func(playerID string) error {
+			_, err := s.StartNextGame(game.ID, playerID, 0)
+			return err
+		},
+		"DrawCard": func(playerID string) error {
+			_, err := s.DrawCard(game.ID, playerID, 0)
+			return err
+		},
+		"ManipulateCard": func(playerID string) error {
+			_, err := s.ManipulateCard(game.ID, playerID, 0, models.Air, cardID, "flip")
+			return err
+		},
+		"DestroyCard": func(playerID string) error {
+			_, err := s.DestroyCard(game.ID, playerID, 0, cardID)
+			return err
+		},
+		"Leave": func(playerID string) error {
+			_, err := s.act(game.ID, 0, models.Action{Type: models.ActionLeave, PlayerID: playerID})
+			return err
+		},
+		"Timeout": func(playerID string) error {
+			_, err := s.act(game.ID, 0, models.Action{Type: models.ActionTimeout, PlayerID: playerID})
+			return err
+		},
+	}
+
+	for name, action := range actions {
+		for _, playerID := range []string{"", "stranger"} {
+			if err := action(playerID); !errors.Is(err, ErrNotInGame) {
+				t.Errorf("%s(%q) = %v, want ErrNotInGame", name, playerID, err)
+			}
+		}
+	}
+
+	after, err := s.GetGame(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Version != game.Version {
+		t.Errorf("rejected actions changed the game from version %d to %d", game.Version, after.Version)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/dfturn/alns/models"
+)
+
+// Manual card actions for sandbox games. They let players carry out card
+// effects by hand and are rejected in strict mode.
+
+var errSandboxOnly = fmt.Errorf("%w: only in sandbox games", ErrWrongMode)
+
+// DrawCard draws one card from the deck
+func (s *GameService) DrawCard(gameID, playerID string, version int) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{Type: models.ActionDrawCard, PlayerID: playerID})
+}
+
+// drawCard moves the top card of the deck to a player's hand
+func (s *GameService) drawCard(game *models.GameState, playerID string) error {
+	player, err := checkSandboxTurn(game, playerID)
+	if err != nil {
+		return err
+	}
+
+	if len(game.Deck) == 0 {
+		return fmt.Errorf("%w: no cards left in deck", ErrInvalidMove)
+	}
+
+	// Draw top card from deck
+	card := game.Deck[0]
+	game.Deck = game.Deck[1:]
+
+	// Add to player's hand
+	player.Hand = append(player.Hand, card)
+
+	return nil
+}
+
+// ManipulateCard allows flipping, destroying, or returning a card to hand
+func (s *GameService) ManipulateCard(gameID, playerID string, version int, theater models.TheaterType, cardID int, manipulation string) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{
+		Type:         models.ActionManipulateCard,
+		PlayerID:     playerID,
+		Theater:      theater,
+		CardID:       cardID,
+		Manipulation: manipulation,
+	})
+}
+
+// manipulateCard flips, destroys or returns an uncovered card. A cardID of
+// 0 targets the top card of the theater.
+func (s *GameService) manipulateCard(game *models.GameState, playerID string, theater models.TheaterType, cardID int, manipulation string) error {
+	if _, err := checkSandboxTurn(game, playerID); err != nil {
+		return err
+	}
+
+	theaterObj := game.Theaters[theater]
+	if theaterObj == nil || len(theaterObj.Cards) == 0 {
+		return fmt.Errorf("%w: no cards in this theater", ErrInvalidMove)
+	}
+
+	// Determine target card
+	targetIndex := len(theaterObj.Cards) - 1
+	if cardID != 0 {
+		targetIndex = -1
+		for i, pc := range theaterObj.Cards {
+			if pc.Card.ID == cardID {
+				targetIndex = i
+				break
+			}
+		}
+		if targetIndex == -1 {
+			return fmt.Errorf("%w: card not found in theater", ErrInvalidMove)
+		}
+
+		// Ensure the selected card is the top card for that player
+		if !isUncovered(theaterObj, targetIndex) {
+			return fmt.Errorf("%w: card is not the top of that player's stack", ErrInvalidMove)
+		}
+	}
+	target := theaterObj.Cards[targetIndex]
+
+	switch manipulation {
+	case "flip":
+		theaterObj.Cards[targetIndex].FaceUp = !target.FaceUp
+
+	case "destroy":
+		theaterObj.Cards = append(theaterObj.Cards[:targetIndex], theaterObj.Cards[targetIndex+1:]...)
+		game.Trash = append(game.Trash, target.Card)
+
+	case "return":
+		// Return card to owner's hand
+		theaterObj.Cards = append(theaterObj.Cards[:targetIndex], theaterObj.Cards[targetIndex+1:]...)
+		if target.PlayerID == game.Player1.ID {
+			game.Player1.Hand = append(game.Player1.Hand, target.Card)
+		} else {
+			game.Player2.Hand = append(game.Player2.Hand, target.Card)
+		}
+
+	default:
+		return fmt.Errorf("%w: invalid action", ErrInvalidMove)
+	}
+
+	return nil
+}
+
+// DestroyCard removes a card from the current player's hand and places it in the trash
+func (s *GameService) DestroyCard(gameID, playerID string, version int, cardID int) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{Type: models.ActionDestroyCard, PlayerID: playerID, CardID: cardID})
+}
+
+// destroyCard moves a card from a player's hand to the trash
+func (s *GameService) destroyCard(game *models.GameState, playerID string, cardID int) error {
+	player, err := checkSandboxTurn(game, playerID)
+	if err != nil {
+		return err
+	}
+
+	for i, c := range player.Hand {
+		if c.ID == cardID {
+			player.Hand = append(player.Hand[:i], player.Hand[i+1:]...)
+			game.Trash = append(game.Trash, c)
+			s.checkBattleEnd(game)
+			return nil
+		}
+	}
+	return ErrCardNotInHand
+}
+
+// checkSandboxTurn checks that a manual action is allowed for playerID and
+// returns their player
+func checkSandboxTurn(game *models.GameState, playerID string) (*models.Player, error) {
+	player, _, err := resolvePlayers(game, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if game.Mode != models.ModeSandbox {
+		return nil, errSandboxOnly
+	}
+
+	if game.Phase != models.PhasePlaying {
+		return nil, fmt.Errorf("%w: game is not in playing phase", ErrWrongPhase)
+	}
+
+	if game.CurrentPlayerID != playerID {
+		return nil, ErrNotYourTurn
+	}
+
+	if len(game.PendingAbilities) > 0 {
+		return nil, ErrAbilityPending
+	}
+	return player, nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/dfturn/alns/models"
+)
+
+const (
+	battleVP     = 6  // Awarded for controlling two theaters
+	winningScore = 12 // VP that ends the game
+)
+
+// UpdateTheaterScores submits a player's totals for both sides of every
+// theater. Submissions are compared with the server's totals and any
+// theater where they differ is flagged as disputed. The battle is decided
+// once both players have submitted the same totals; until then either
+// player may submit again or accept the server's totals.
+func (s *GameService) UpdateTheaterScores(gameID, playerID string, version int, totals map[models.TheaterType]models.TheaterScore) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{Type: models.ActionUpdateScores, PlayerID: playerID, Totals: totals})
+}
+
+// updateTheaterScores records a player's submitted totals
+func (s *GameService) updateTheaterScores(game *models.GameState, playerID string, totals map[models.TheaterType]models.TheaterScore) error {
+	if err := checkScoring(game, playerID); err != nil {
+		return err
+	}
+
+	submission := make(map[models.TheaterType]models.TheaterScore, len(totals))
+	for theater, score := range totals {
+		if game.TheaterScores[theater] == nil {
+			return fmt.Errorf("%w: %q", ErrUnknownTheater, theater)
+		}
+		if score.Player1Total < 0 || score.Player2Total < 0 {
+			return fmt.Errorf("%w: theater scores cannot be negative", ErrInvalidScores)
+		}
+		submission[theater] = models.TheaterScore{Player1Total: score.Player1Total, Player2Total: score.Player2Total}
+	}
+	if len(submission) != len(game.TheaterScores) {
+		return fmt.Errorf("%w: scores are required for every theater", ErrInvalidScores)
+	}
+
+	if game.ScoreSubmissions == nil {
+		game.ScoreSubmissions = make(map[string]map[models.TheaterType]models.TheaterScore)
+	}
+	game.ScoreSubmissions[playerID] = submission
+	game.DisputedTheaters = disputedTheaters(game)
+
+	// Players who agree with each other overrule the server; the theaters
+	// where they differ from it stay flagged
+	other := game.ScoreSubmissions[opponentID(game, playerID)]
+	if other != nil && sameTotals(submission, other) {
+		for theater, score := range submission {
+			game.TheaterScores[theater] = &models.TheaterScore{Player1Total: score.Player1Total, Player2Total: score.Player2Total}
+		}
+		s.calculateBattleWinner(game)
+	}
+
+	return nil
+}
+
+// AcceptServerScores decides the battle with the server's totals
+func (s *GameService) AcceptServerScores(gameID, playerID string, version int) (*models.GameState, error) {
+	return s.act(gameID, version, models.Action{Type: models.ActionAcceptScores, PlayerID: playerID})
+}
+
+// acceptServerScores decides the battle with the totals computed from the board
+func (s *GameService) acceptServerScores(game *models.GameState, playerID string) error {
+	if err := checkScoring(game, playerID); err != nil {
+		return err
+	}
+
+	s.calculateBattleWinner(game)
+	return nil
+}
+
+// checkScoring checks that a player can still take part in scoring the
+// battle. A battle that has been decided, by scoring or by a withdrawal,
+// is left unchanged.
+func checkScoring(game *models.GameState, playerID string) error {
+	if _, _, err := resolvePlayers(game, playerID); err != nil {
+		return err
+	}
+	if game.Phase != models.PhaseScoring {
+		return fmt.Errorf("%w: game is not in scoring phase", ErrWrongPhase)
+	}
+	if game.BattleWinnerID != "" {
+		return errNoChange
+	}
+
+	// Initialize theater scores if needed
+	if game.TheaterScores == nil {
+		game.TheaterScores = ComputeTheaterScores(game)
+	}
+	return nil
+}
+
+// disputedTheaters lists the theaters, in board order, where a submission
+// differs from the server's totals. Two submissions that differ from each
+// other always differ from the server in that theater too.
+func disputedTheaters(game *models.GameState) []models.TheaterType {
+	var disputed []models.TheaterType
+	for _, theater := range game.TheaterOrder {
+		server := game.TheaterScores[theater]
+		for _, submission := range game.ScoreSubmissions {
+			score := submission[theater]
+			if score.Player1Total != server.Player1Total || score.Player2Total != server.Player2Total {
+				disputed = append(disputed, theater)
+				break
+			}
+		}
+	}
+	return disputed
+}
+
+// sameTotals reports whether two submissions agree on every theater
+func sameTotals(a, b map[models.TheaterType]models.TheaterScore) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for theater, score := range a {
+		if b[theater] != score {
+			return false
+		}
+	}
+	return true
+}
+
+// calculateBattleWinner assigns every theater a controller and awards the
+// battle to whoever controls two of them. With ties going to the first
+// player, someone always does.
+func (s *GameService) calculateBattleWinner(game *models.GameState) {
+	held := make(map[string]int)
+	for _, t := range game.TheaterOrder {
+		score := game.TheaterScores[t]
+		score.ControllerID = TheaterController(game, score)
+		held[score.ControllerID]++
+	}
+
+	winnerID := game.Player1.ID
+	if held[game.Player2.ID] > held[game.Player1.ID] {
+		winnerID = game.Player2.ID
+	}
+	awardBattle(game, models.BattleResult{WinnerID: winnerID, VP: battleVP})
+}
+
+// TheaterController returns the player who controls a theater: the one
+// with the higher total, or the battle's first player on a tie
+func TheaterController(game *models.GameState, score *models.TheaterScore) string {
+	switch {
+	case score.Player1Total > score.Player2Total:
+		return game.Player1.ID
+	case score.Player2Total > score.Player1Total:
+		return game.Player2.ID
+	}
+	return game.FirstPlayerID
+}
+
+// awardBattle records the result of the current battle, gives its VP to
+// the winner and ends the game once they reach the winning score
+func awardBattle(game *models.GameState, result models.BattleResult) {
+	result.BattleNumber = game.BattleNumber
+	result.FirstPlayerID = game.FirstPlayerID
+
+	// Withdrawals end the battle before the theaters are scored
+	scores := game.TheaterScores
+	if scores == nil {
+		scores = ComputeTheaterScores(game)
+	}
+	result.TheaterScores = make(map[models.TheaterType]models.TheaterScore, len(scores))
+	for t, score := range scores {
+		result.TheaterScores[t] = *score
+	}
+	for _, t := range game.TheaterOrder {
+		result.Board = append(result.Board, models.Theater{
+			Type:  t,
+			Cards: append([]models.PlayedCard{}, game.Theaters[t].Cards...),
+		})
+	}
+	game.BattleResults = append(game.BattleResults, result)
+
+	game.BattleWinnerID = result.WinnerID
+	if result.WinnerID == game.Player1.ID {
+		game.Player1.Score += result.VP
+	} else {
+		game.Player2.Score += result.VP
+	}
+
+	if game.Player1.Score >= winningScore || game.Player2.Score >= winningScore {
+		game.Phase = models.PhaseGameOver
+		game.WinnerID = result.WinnerID
+	}
+}
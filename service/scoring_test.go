@@ -0,0 +1,296 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+// newScoringBoard returns a detached game whose battle is over and waiting
+// to be scored, with firstPlayerID having played first
+func newScoringBoard(firstPlayerID string) *models.GameState {
+	game := newBoard(nil, nil)
+	game.Phase = models.PhaseScoring
+	game.FirstPlayerID = firstPlayerID
+	game.CurrentPlayerID = firstPlayerID
+	return game
+}
+
+func TestEmptyTheatersGoToFirstPlayer(t *testing.T) {
+	for _, first := range []string{"p1", "p2"} {
+		game := newScoringBoard(first)
+		mustApply(t, game, models.Action{Type: models.ActionAcceptScores, PlayerID: "p1"})
+
+		for _, theater := range game.TheaterOrder {
+			score := game.TheaterScores[theater]
+			if score.Player1Total != 0 || score.Player2Total != 0 || score.ControllerID != first {
+				t.Errorf("first player %s: empty %s = %+v, want 0-0 controlled by %s", first, theater, *score, first)
+			}
+		}
+		if game.BattleWinnerID != first {
+			t.Errorf("first player %s: empty board won by %q", first, game.BattleWinnerID)
+		}
+	}
+}
+
+func TestTiedTheatersGoToFirstPlayer(t *testing.T) {
+	game := newScoringBoard("p2")
+	place(game, models.Air, "p1", 6, false)
+	place(game, models.Air, "p2", 5, false)
+	place(game, models.Land, "p1", 12, true)
+
+	mustApply(t, game, models.Action{Type: models.ActionAcceptScores, PlayerID: "p1"})
+
+	want := map[models.TheaterType]string{models.Air: "p2", models.Land: "p1", models.Sea: "p2"}
+	for theater, controller := range want {
+		if got := game.TheaterScores[theater].ControllerID; got != controller {
+			t.Errorf("%s controlled by %q, want %q", theater, got, controller)
+		}
+	}
+	if game.BattleWinnerID != "p2" || game.Player2.Score != battleVP {
+		t.Errorf("battle won by %q with %d VP, want p2 with %d", game.BattleWinnerID, game.Player2.Score, battleVP)
+	}
+}
+
+func TestEveryScoredBattleHasWinnerAndControllers(t *testing.T) {
+	boards := []func(*models.GameState){
+		func(game *models.GameState) {},
+		func(game *models.GameState) {
+			place(game, models.Air, "p1", 6, true)
+			place(game, models.Land, "p2", 12, true)
+		},
+		func(game *models.GameState) {
+			place(game, models.Air, "p1", 1, false)
+			place(game, models.Air, "p2", 2, false)
+			place(game, models.Land, "p1", 7, false)
+			place(game, models.Land, "p2", 8, false)
+			place(game, models.Sea, "p1", 13, false)
+			place(game, models.Sea, "p2", 14, false)
+		},
+		func(game *models.GameState) {
+			place(game, models.Sea, "p2", 18, true)
+			place(game, models.Sea, "p2", 17, true)
+		},
+	}
+
+	for i, setup := range boards {
+		for _, first := range []string{"p1", "p2"} {
+			game := newScoringBoard(first)
+			setup(game)
+			mustApply(t, game, models.Action{Type: models.ActionAcceptScores, PlayerID: first})
+
+			if game.BattleWinnerID != "p1" && game.BattleWinnerID != "p2" {
+				t.Errorf("board %d, first player %s: battle won by %q", i, first, game.BattleWinnerID)
+			}
+			if len(game.BattleResults) != 1 {
+				t.Fatalf("board %d, first player %s: %d battle results, want 1", i, first, len(game.BattleResults))
+			}
+			result := game.BattleResults[0]
+			held := map[string]int{}
+			for _, theater := range game.TheaterOrder {
+				controller := result.TheaterScores[theater].ControllerID
+				if controller != "p1" && controller != "p2" {
+					t.Errorf("board %d, first player %s: %s has no controller", i, first, theater)
+				}
+				held[controller]++
+			}
+			if result.WinnerID != game.BattleWinnerID || held[result.WinnerID] < 2 {
+				t.Errorf("board %d, first player %s: %s won holding %d theaters", i, first, result.WinnerID, held[result.WinnerID])
+			}
+		}
+	}
+}
+
+// newDisputeBoard returns a battle the server scores as p1 winning Air
+// 6-0 and the empty Sea, and p2 winning Land 0-6
+func newDisputeBoard() *models.GameState {
+	game := newScoringBoard("p1")
+	place(game, models.Air, "p1", 6, true)
+	place(game, models.Land, "p2", 12, true)
+	return game
+}
+
+func serverTotals() map[models.TheaterType]models.TheaterScore {
+	return map[models.TheaterType]models.TheaterScore{
+		models.Air:  {Player1Total: 6},
+		models.Land: {Player2Total: 6},
+		models.Sea:  {},
+	}
+}
+
+func submitAction(playerID string, totals map[models.TheaterType]models.TheaterScore) models.Action {
+	return models.Action{Type: models.ActionUpdateScores, PlayerID: playerID, Totals: totals}
+}
+
+func TestScoreSubmissionDifferingFromServerIsDisputed(t *testing.T) {
+	game := newDisputeBoard()
+	claimed := serverTotals()
+	claimed[models.Sea] = models.TheaterScore{Player2Total: 1}
+
+	mustApply(t, game, submitAction("p2", claimed))
+	if len(game.DisputedTheaters) != 1 || game.DisputedTheaters[0] != models.Sea {
+		t.Errorf("disputed theaters = %v, want [sea]", game.DisputedTheaters)
+	}
+	if game.BattleWinnerID != "" {
+		t.Errorf("one submission decided the battle for %q", game.BattleWinnerID)
+	}
+
+	// Agreeing with the server after all clears the dispute
+	mustApply(t, game, submitAction("p2", serverTotals()))
+	if len(game.DisputedTheaters) != 0 {
+		t.Errorf("disputed theaters after correcting = %v, want none", game.DisputedTheaters)
+	}
+}
+
+func TestDifferingSubmissionsAreDisputed(t *testing.T) {
+	game := newDisputeBoard()
+	mine := serverTotals()
+	mine[models.Air] = models.TheaterScore{Player1Total: 7}
+	theirs := serverTotals()
+	theirs[models.Air] = models.TheaterScore{Player1Total: 5}
+
+	mustApply(t, game, submitAction("p1", mine), submitAction("p2", theirs))
+	if len(game.DisputedTheaters) != 1 || game.DisputedTheaters[0] != models.Air {
+		t.Errorf("disputed theaters = %v, want [air]", game.DisputedTheaters)
+	}
+	if game.BattleWinnerID != "" || game.Phase != models.PhaseScoring {
+		t.Errorf("disagreeing submissions decided the battle for %q", game.BattleWinnerID)
+	}
+}
+
+func TestMatchingSubmissionsDecideBattle(t *testing.T) {
+	game := newDisputeBoard()
+
+	mustApply(t, game, submitAction("p1", serverTotals()))
+	if game.BattleWinnerID != "" {
+		t.Fatalf("one submission decided the battle for %q", game.BattleWinnerID)
+	}
+	mustApply(t, game, submitAction("p2", serverTotals()))
+	if game.BattleWinnerID != "p1" || game.Player1.Score != battleVP {
+		t.Errorf("battle won by %q with %d VP, want p1 with %d", game.BattleWinnerID, game.Player1.Score, battleVP)
+	}
+}
+
+func TestAgreedSubmissionsOverruleServer(t *testing.T) {
+	game := newDisputeBoard()
+	agreed := serverTotals()
+	agreed[models.Sea] = models.TheaterScore{Player2Total: 3}
+
+	mustApply(t, game, submitAction("p1", agreed), submitAction("p2", agreed))
+	if game.BattleWinnerID != "p2" {
+		t.Errorf("battle won by %q, want p2 on the agreed totals", game.BattleWinnerID)
+	}
+	if len(game.DisputedTheaters) != 1 || game.DisputedTheaters[0] != models.Sea {
+		t.Errorf("disputed theaters = %v, want sea to stay flagged", game.DisputedTheaters)
+	}
+}
+
+func TestAcceptScoresDecidesDisputedBattle(t *testing.T) {
+	game := newDisputeBoard()
+	claimed := serverTotals()
+	claimed[models.Sea] = models.TheaterScore{Player2Total: 3}
+
+	mustApply(t, game,
+		submitAction("p2", claimed),
+		models.Action{Type: models.ActionAcceptScores, PlayerID: "p2"},
+	)
+	if game.BattleWinnerID != "p1" {
+		t.Errorf("battle won by %q, want p1 on the server's totals", game.BattleWinnerID)
+	}
+
+	// Late submissions leave the decided battle alone
+	for _, playerID := range []string{"p1", "p2"} {
+		if err := ApplyAction(game, submitAction(playerID, claimed)); !errors.Is(err, errNoChange) {
+			t.Errorf("late submission by %s = %v, want %v", playerID, err, errNoChange)
+		}
+	}
+	if game.BattleWinnerID != "p1" || len(game.BattleResults) != 1 {
+		t.Errorf("late submissions changed the battle: won by %q with %d results", game.BattleWinnerID, len(game.BattleResults))
+	}
+}
+
+// startNextBattle deals the next battle of a detached game
+func startNextBattle(t *testing.T, game *models.GameState) {
+	t.Helper()
+	mustApply(t, game, models.Action{Type: models.ActionNextBattle, PlayerID: "p1", Deal: nextDeal(game)})
+}
+
+func TestScoredBattleIsRecorded(t *testing.T) {
+	game := newScoringBoard("p2")
+	game.BattleNumber = 2
+	place(game, models.Air, "p1", 6, true)
+	place(game, models.Land, "p2", 12, true)
+	place(game, models.Sea, "p1", 18, false)
+	mustApply(t, game, models.Action{Type: models.ActionAcceptScores, PlayerID: "p1"})
+
+	want := models.BattleResult{
+		BattleNumber:  2,
+		FirstPlayerID: "p2",
+		WinnerID:      "p1",
+		VP:            battleVP,
+		TheaterScores: map[models.TheaterType]models.TheaterScore{
+			models.Air:  {Player1Total: 6, ControllerID: "p1"},
+			models.Land: {Player2Total: 6, ControllerID: "p2"},
+			models.Sea:  {Player1Total: 2, ControllerID: "p1"},
+		},
+		Board: []models.Theater{
+			{Type: models.Air, Cards: []models.PlayedCard{{Card: testCard(6), FaceUp: true, PlayerID: "p1"}}},
+			{Type: models.Land, Cards: []models.PlayedCard{{Card: testCard(12), FaceUp: true, PlayerID: "p2"}}},
+			{Type: models.Sea, Cards: []models.PlayedCard{{Card: testCard(18), PlayerID: "p1"}}},
+		},
+	}
+	if len(game.BattleResults) != 1 || !reflect.DeepEqual(game.BattleResults[0], want) {
+		t.Fatalf("battle results = %+v, want [%+v]", game.BattleResults, want)
+	}
+
+	// The record outlives the board it was taken from
+	startNextBattle(t, game)
+	mustApply(t, game, playAction(game.CurrentPlayerID, currentHand(game)[0].ID, models.Air, false))
+	if len(game.BattleResults) != 1 || !reflect.DeepEqual(game.BattleResults[0], want) {
+		t.Errorf("battle results after the next battle started = %+v, want [%+v]", game.BattleResults, want)
+	}
+}
+
+func TestWithdrawnBattleIsRecorded(t *testing.T) {
+	game := newBoard([]int{1, 7}, []int{2, 8, 14})
+	place(game, models.Land, "p1", 12, true)
+	place(game, models.Sea, "p2", 16, false)
+	mustApply(t, game, models.Action{Type: models.ActionWithdraw, PlayerID: "p1"})
+
+	want := models.BattleResult{
+		BattleNumber:     1,
+		FirstPlayerID:    "p1",
+		WinnerID:         "p2",
+		VP:               3,
+		WithdrewPlayerID: "p1",
+		CardsRemaining:   2,
+		TheaterScores: map[models.TheaterType]models.TheaterScore{
+			models.Air:  {},
+			models.Land: {Player1Total: 6},
+			models.Sea:  {Player2Total: 2},
+		},
+		Board: []models.Theater{
+			{Type: models.Air, Cards: []models.PlayedCard{}},
+			{Type: models.Land, Cards: []models.PlayedCard{{Card: testCard(12), FaceUp: true, PlayerID: "p1"}}},
+			{Type: models.Sea, Cards: []models.PlayedCard{{Card: testCard(16), PlayerID: "p2"}}},
+		},
+	}
+	if len(game.BattleResults) != 1 || !reflect.DeepEqual(game.BattleResults[0], want) {
+		t.Fatalf("battle results = %+v, want [%+v]", game.BattleResults, want)
+	}
+
+	startNextBattle(t, game)
+	if len(game.BattleResults) != 1 || !reflect.DeepEqual(game.BattleResults[0], want) {
+		t.Errorf("battle results after the next battle started = %+v, want [%+v]", game.BattleResults, want)
+	}
+}
+
+// currentHand returns the hand of the player whose turn it is
+func currentHand(game *models.GameState) []models.Card {
+	if game.CurrentPlayerID == game.Player1.ID {
+		return game.Player1.Hand
+	}
+	return game.Player2.Hand
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"time"
+
+	"github.com/dfturn/alns/models"
+	"github.com/google/uuid"
+)
+
+// newSeat issues fresh credentials for a seat
+func newSeat() models.Seat {
+	return models.Seat{
+		SessionID:    uuid.New().String(),
+		RecoveryCode: newRecoveryCode(),
+	}
+}
+
+// newRecoveryCode returns a random code like "K7QM-2XPD". Codes come from
+// crypto/rand rather than the service's seeded source, which is
+// predictable.
+func newRecoveryCode() string {
+	const charset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	code := make([]byte, 0, 9)
+	for i, c := range b {
+		if i == 4 {
+			code = append(code, '-')
+		}
+		code = append(code, charset[int(c)%len(charset)])
+	}
+	return string(code)
+}
+
+// ReclaimSeat gives a player who lost their session their seat back. The
+// seat is found by its recovery code and issued a new session and recovery
+// code, so tokens for the old session stop working.
+func (s *GameService) ReclaimSeat(roomID, recoveryCode string) (*models.Room, string, error) {
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+
+	stored, err := s.loadRoom(roomID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	room := cloneRoom(stored)
+	if room.Status != models.RoomStatusWaiting && room.Status != models.RoomStatusPlaying {
+		return nil, "", ErrRoomClosed
+	}
+
+	playerID := ""
+	for id, seat := range room.Seats {
+		if subtle.ConstantTimeCompare([]byte(seat.RecoveryCode), []byte(recoveryCode)) == 1 {
+			playerID = id
+		}
+	}
+	if playerID == "" {
+		return nil, "", ErrInvalidRecoveryCode
+	}
+
+	room.Seats[playerID] = newSeat()
+	room.UpdatedAt = time.Now()
+	if err := s.store.SaveRoom(room); err != nil {
+		return nil, "", err
+	}
+	return cloneRoom(room), playerID, nil
+}
+
+// CheckSession reports whether sessionID is the current session of a
+// player's seat, or of a spectator, in a room
+func (s *GameService) CheckSession(roomID, playerID, sessionID string) (bool, error) {
+	room, err := s.GetRoom(roomID)
+	if err != nil {
+		return false, err
+	}
+
+	seat, ok := room.Seats[playerID]
+	if !ok {
+		seat, ok = room.Spectators[playerID]
+	}
+	return ok && subtle.ConstantTimeCompare([]byte(seat.SessionID), []byte(sessionID)) == 1, nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestReclaimRejectsWrongRecoveryCode(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{})
+	room, err := s.GetRoom(game.RoomID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, code := range []string{"", "AAAA-AAAA", room.Seats[game.Player1.ID].RecoveryCode + "X"} {
+		if _, _, err := s.ReclaimSeat(room.ID, code); !errors.Is(err, ErrInvalidRecoveryCode) {
+			t.Errorf("ReclaimSeat(%q) = %v, want ErrInvalidRecoveryCode", code, err)
+		}
+	}
+
+	after, err := s.GetRoom(room.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for playerID, seat := range room.Seats {
+		if after.Seats[playerID] != seat {
+			t.Errorf("a failed reclaim changed the seat of %s", playerID)
+		}
+	}
+}
+
+func TestReclaimIssuesNewCredentials(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{})
+	room, err := s.GetRoom(game.RoomID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if room.Status != models.RoomStatusPlaying {
+		t.Fatalf("room status = %s, want %s", room.Status, models.RoomStatusPlaying)
+	}
+	playerID := game.Player2.ID
+	old := room.Seats[playerID]
+
+	reclaimed, gotID, err := s.ReclaimSeat(room.ID, old.RecoveryCode)
+	if err != nil {
+		t.Fatalf("ReclaimSeat: %v", err)
+	}
+	if gotID != playerID {
+		t.Errorf("reclaimed the seat of %q, want %q", gotID, playerID)
+	}
+	seat := reclaimed.Seats[playerID]
+	if seat.RecoveryCode == old.RecoveryCode || seat.SessionID == old.SessionID {
+		t.Error("reclaiming kept the old credentials")
+	}
+	if reclaimed.Seats[game.Player1.ID] != room.Seats[game.Player1.ID] {
+		t.Error("reclaiming changed the opponent's seat")
+	}
+
+	if ok, err := s.CheckSession(room.ID, playerID, old.SessionID); err != nil || ok {
+		t.Errorf("old session check = %v, %v, want it replaced", ok, err)
+	}
+	if ok, err := s.CheckSession(room.ID, playerID, seat.SessionID); err != nil || !ok {
+		t.Errorf("new session check = %v, %v, want it accepted", ok, err)
+	}
+	if _, _, err := s.ReclaimSeat(room.ID, old.RecoveryCode); !errors.Is(err, ErrInvalidRecoveryCode) {
+		t.Errorf("reclaiming with the old code = %v, want ErrInvalidRecoveryCode", err)
+	}
+}
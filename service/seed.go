@@ -0,0 +1,33 @@
+package service
+
+import (
+	"math/rand"
+
+	"github.com/dfturn/alns/models"
+)
+
+// seededRand returns the nth independent random stream derived from a
+// game's seed
+func seededRand(seed int64, n int) *rand.Rand {
+	streams := rand.New(rand.NewSource(seed))
+	for i := 0; i < n; i++ {
+		streams.Int63()
+	}
+	return rand.New(rand.NewSource(streams.Int63()))
+}
+
+// firstPlayerRand decides who goes first in a game with this seed
+func firstPlayerRand(seed int64) *rand.Rand {
+	return seededRand(seed, 0)
+}
+
+// nextDeal shuffles the deck for a game's next deal. The cards depend only
+// on the seed and how many deals came before, so games with the same seed
+// are dealt the same hands however they are played.
+func nextDeal(game *models.GameState) []models.Card {
+	deck := models.AllCards()
+	seededRand(game.Seed, game.Deals+1).Shuffle(len(deck), func(i, j int) {
+		deck[i], deck[j] = deck[j], deck[i]
+	})
+	return deck
+}
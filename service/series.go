@@ -0,0 +1,67 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/dfturn/alns/models"
+)
+
+// validateBestOf checks the series length of a new room and fills in its
+// default. Series need an odd number of games so that one player always
+// ends up with a majority.
+func validateBestOf(options *models.RoomOptions) error {
+	switch {
+	case options.BestOf == 0:
+		options.BestOf = 1
+	case options.BestOf < 0 || options.BestOf%2 == 0:
+		return fmt.Errorf("%w: a series must be an odd number of games", ErrInvalidOptions)
+	}
+	return nil
+}
+
+// newSeries starts a series of bestOf games with none played
+func newSeries(bestOf int) *models.Series {
+	if bestOf < 1 {
+		bestOf = 1
+	}
+	return &models.Series{
+		BestOf: bestOf,
+		Games:  []models.GameResult{},
+		Wins:   map[string]int{},
+	}
+}
+
+// updateSeries records a game that just ended in its series, and decides
+// the series once a player has won a majority of its games. A player who
+// leaves the room concedes the rest of the series, even between games.
+func updateSeries(game *models.GameState, wasOver bool) {
+	series := game.Series
+	if series == nil || series.WinnerID != "" || game.Phase != models.PhaseGameOver {
+		return
+	}
+
+	if !wasOver {
+		series.Games = append(series.Games, models.GameResult{
+			GameNumber: len(series.Games) + 1,
+			WinnerID:   game.WinnerID,
+			Scores: map[string]int{
+				game.Player1.ID: game.Player1.Score,
+				game.Player2.ID: game.Player2.Score,
+			},
+			Battles:           len(game.BattleResults),
+			ForfeitedPlayerID: game.ForfeitedPlayerID,
+			LeftPlayerID:      game.LeftPlayerID,
+		})
+		series.Wins[game.WinnerID]++
+	}
+
+	switch {
+	case series.Wins[game.WinnerID] > series.BestOf/2:
+		series.WinnerID = game.WinnerID
+	case game.LeftPlayerID != "":
+		_, opponent, err := resolvePlayers(game, game.LeftPlayerID)
+		if err == nil {
+			series.WinnerID = opponent.ID
+		}
+	}
+}
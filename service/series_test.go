@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestNextGameKeepsDecidedSeries(t *testing.T) {
+	s, game := newTestGame(t, models.RoomOptions{BestOf: 3})
+	game = cloneGame(game)
+	winnerID := game.Player1.ID
+
+	game.Phase = models.PhaseGameOver
+	game.WinnerID = winnerID
+	game.Series.Games = []models.GameResult{{GameNumber: 1, WinnerID: winnerID}, {GameNumber: 2, WinnerID: winnerID}}
+	game.Series.Wins = map[string]int{winnerID: 2}
+	game.Series.WinnerID = winnerID
+
+	if err := s.startNextGame(game, game.Player2.ID, nextDeal(game)); err != nil {
+		t.Fatalf("startNextGame: %v", err)
+	}
+
+	if len(game.SeriesHistory) != 1 {
+		t.Fatalf("series history has %d series, want 1", len(game.SeriesHistory))
+	}
+	decided := game.SeriesHistory[0]
+	if decided.WinnerID != winnerID || len(decided.Games) != 2 || decided.Wins[winnerID] != 2 {
+		t.Errorf("decided series = %+v, want the two-game win kept", decided)
+	}
+	if game.Series.BestOf != 3 || len(game.Series.Games) != 0 || game.Series.WinnerID != "" {
+		t.Errorf("new series = %+v, want an empty best of 3", game.Series)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/dfturn/alns/models"
+	"github.com/google/uuid"
+)
+
+// Spectate adds a spectator to a room that allows them and returns the
+// room along with the spectator's ID. The spectator's session is in
+// room.Spectators.
+func (s *GameService) Spectate(roomID string) (*models.Room, string, error) {
+	s.roomsMu.Lock()
+	defer s.roomsMu.Unlock()
+
+	stored, err := s.loadRoom(roomID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	room := cloneRoom(stored)
+	if !room.Options.AllowSpectators {
+		return nil, "", ErrSpectatorsNotAllowed
+	}
+	if room.Status != models.RoomStatusWaiting && room.Status != models.RoomStatusPlaying {
+		return nil, "", ErrRoomClosed
+	}
+
+	spectatorID := uuid.New().String()
+	if room.Spectators == nil {
+		room.Spectators = make(map[string]models.Seat)
+	}
+	room.Spectators[spectatorID] = models.Seat{SessionID: uuid.New().String()}
+	if err := s.store.SaveRoom(room); err != nil {
+		return nil, "", err
+	}
+	return cloneRoom(room), spectatorID, nil
+}
+
+// IsSpectator reports whether viewerID is watching a room rather than
+// playing in it
+func IsSpectator(room *models.Room, viewerID string) bool {
+	_, ok := room.Spectators[viewerID]
+	return ok
+}
+
+// SpectatorView builds the view of a game that its spectators see. It
+// shows neither hand nor any face-down card and, while the game is in
+// progress, lags the room's SpectatorDelay moves behind so that it cannot
+// be used to pass information to a player.
+func (s *GameService) SpectatorView(gameID string) (*models.GameView, error) {
+	game, events, delay, err := s.spectatorSnapshot(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if delay > 0 && game.Phase != models.PhaseGameOver {
+		index := len(events) - 1 - delay
+		if index < 0 {
+			index = 0
+		}
+		if game, err = s.replay(events, index); err != nil {
+			return nil, err
+		}
+	}
+
+	view := NewGameView(game, "")
+	view.Spectating = true
+	if delay > 0 {
+		// A clock replayed from the past would show time that has long
+		// since run out
+		view.Clock = nil
+	}
+	return view, nil
+}
+
+// spectatorSnapshot reads a game, its move log and its room's spectator
+// delay under the game lock, so that the delayed view is replayed from the
+// very log the game was read with
+func (s *GameService) spectatorSnapshot(gameID string) (*models.GameState, []models.GameEvent, int, error) {
+	lock := s.gameLock(gameID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	game, err := s.loadGame(gameID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	events, err := s.store.ListEvents(gameID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Room options never change once the room is created, so they are read
+	// straight from the store rather than under roomsMu, which must not be
+	// taken while a game lock is held
+	room, err := s.store.GetRoom(game.RoomID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil, 0, ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return cloneGame(game), events, room.Options.SpectatorDelay, nil
+}
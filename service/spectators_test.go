@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestSpectatorViewLagsBehindByDelay(t *testing.T) {
+	const delay = 2
+	s, game := newTestGame(t, models.RoomOptions{AllowSpectators: true, SpectatorDelay: delay})
+
+	for i := 0; i < 4; i++ {
+		player := game.Player1
+		if game.CurrentPlayerID == game.Player2.ID {
+			player = game.Player2
+		}
+		var err error
+		game, err = s.PlayCard(game.ID, player.ID, game.Version, player.Hand[0].ID, models.Air, false)
+		if err != nil {
+			t.Fatalf("move %d: %v", i, err)
+		}
+
+		view, err := s.SpectatorView(game.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := game.Version - delay
+		if want < 1 {
+			want = 1
+		}
+		if view.Version != want {
+			t.Errorf("after move %d at version %d the spectator sees version %d, want %d", i, game.Version, view.Version, want)
+		}
+		if !view.Spectating || len(view.Player1.Hand) != 0 || len(view.Player2.Hand) != 0 {
+			t.Errorf("spectator view shows a hand: %+v, %+v", view.Player1, view.Player2)
+		}
+
+		past, err := s.ReplayGame(game.ID, want-1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cards := len(view.Theaters[models.Air].Cards); cards != len(past.Theaters[models.Air].Cards) {
+			t.Errorf("spectator sees %d cards in air, want the %d of version %d", cards, len(past.Theaters[models.Air].Cards), want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/dfturn/alns/models"
+)
+
+// ErrNotFound is returned by a Store when a room or game does not exist
+var ErrNotFound = errors.New("not found")
+
+// Store persists rooms and games. GameService serializes access to each
+// game itself, so implementations only need to be safe for concurrent use
+// across different keys.
+type Store interface {
+	GetRoom(roomID string) (*models.Room, error)
+	SaveRoom(room *models.Room) error
+	DeleteRoom(roomID string) error
+	ListRooms() ([]*models.Room, error)
+
+	GetGame(gameID string) (*models.GameState, error)
+	// SaveGame stores a game and appends the event that produced it to the
+	// game's move log, so that the two never get out of step
+	SaveGame(game *models.GameState, event models.GameEvent) error
+	// SaveRoomAndGame is SaveGame for changes that also update the game's
+	// room. Both are stored, or neither is.
+	SaveRoomAndGame(room *models.Room, game *models.GameState, event models.GameEvent) error
+	DeleteGame(gameID string) error
+	ListGames() ([]*models.GameState, error)
+
+	// ListEvents returns a game's move log in order
+	ListEvents(gameID string) ([]models.GameEvent, error)
+}
+
+// MemoryStore keeps rooms and games in memory. Everything is lost when the
+// process exits.
+type MemoryStore struct {
+	rooms sync.Map // map[string]*models.Room
+	games sync.Map // map[string]*models.GameState
+
+	eventsMu sync.Mutex
+	events   map[string][]models.GameEvent
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{events: make(map[string][]models.GameEvent)}
+}
+
+// GetRoom retrieves a room by ID
+func (m *MemoryStore) GetRoom(roomID string) (*models.Room, error) {
+	value, ok := m.rooms.Load(roomID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return value.(*models.Room), nil
+}
+
+// SaveRoom stores a room
+func (m *MemoryStore) SaveRoom(room *models.Room) error {
+	m.rooms.Store(room.ID, room)
+	return nil
+}
+
+// DeleteRoom removes a room
+func (m *MemoryStore) DeleteRoom(roomID string) error {
+	m.rooms.Delete(roomID)
+	return nil
+}
+
+// ListRooms returns every stored room
+func (m *MemoryStore) ListRooms() ([]*models.Room, error) {
+	var rooms []*models.Room
+	m.rooms.Range(func(_, value any) bool {
+		rooms = append(rooms, value.(*models.Room))
+		return true
+	})
+	return rooms, nil
+}
+
+// GetGame retrieves a game by ID
+func (m *MemoryStore) GetGame(gameID string) (*models.GameState, error) {
+	value, ok := m.games.Load(gameID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return value.(*models.GameState), nil
+}
+
+// SaveGame stores a game and appends an event to its move log
+func (m *MemoryStore) SaveGame(game *models.GameState, event models.GameEvent) error {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	m.games.Store(game.ID, game)
+	m.events[game.ID] = append(m.events[game.ID], event)
+	return nil
+}
+
+// SaveRoomAndGame stores a room along with a game and its event
+func (m *MemoryStore) SaveRoomAndGame(room *models.Room, game *models.GameState, event models.GameEvent) error {
+	m.rooms.Store(room.ID, room)
+	return m.SaveGame(game, event)
+}
+
+// DeleteGame removes a game
+func (m *MemoryStore) DeleteGame(gameID string) error {
+	m.games.Delete(gameID)
+	m.eventsMu.Lock()
+	delete(m.events, gameID)
+	m.eventsMu.Unlock()
+	return nil
+}
+
+// ListGames returns every stored game
+func (m *MemoryStore) ListGames() ([]*models.GameState, error) {
+	var games []*models.GameState
+	m.games.Range(func(_, value any) bool {
+		games = append(games, value.(*models.GameState))
+		return true
+	})
+	return games, nil
+}
+
+// ListEvents returns a copy of a game's move log
+func (m *MemoryStore) ListEvents(gameID string) ([]models.GameEvent, error) {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	return append([]models.GameEvent(nil), m.events[gameID]...), nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import "github.com/dfturn/alns/models"
+
+const (
+	faceDownStrength  = 2
+	escalatedStrength = 4
+	coverFireStrength = 4
+	supportBonus      = 3
+)
+
+// ComputeTheaterScores calculates each player's strength in every theater
+// from the cards on the board. Aerodrome, Air Drop, Containment and Blockade
+// only change where cards end up, so they are already reflected in the board.
+func ComputeTheaterScores(game *models.GameState) map[models.TheaterType]*models.TheaterScore {
+	scores := make(map[models.TheaterType]*models.TheaterScore)
+	for _, t := range game.TheaterOrder {
+		scores[t] = &models.TheaterScore{
+			Player1Total: theaterStrength(game, t, game.Player1.ID),
+			Player2Total: theaterStrength(game, t, game.Player2.ID),
+		}
+	}
+	return scores
+}
+
+// theaterStrength sums a player's strength in a single theater
+func theaterStrength(game *models.GameState, t models.TheaterType, playerID string) int {
+	theater := game.Theaters[t]
+	if theater == nil {
+		return 0
+	}
+
+	escalated := false
+	for _, bc := range activeAbilityCards(game, models.AbilityEscalation) {
+		if bc.Played.PlayerID == playerID {
+			escalated = true
+			break
+		}
+	}
+
+	// Cover Fire sets every card beneath it in the owner's stack to strength 4
+	coveredBelow := -1
+	for i, pc := range theater.Cards {
+		if pc.PlayerID == playerID && pc.FaceUp && pc.Card.Ability == models.AbilityCoverFire {
+			coveredBelow = i
+		}
+	}
+
+	total := 0
+	for i, pc := range theater.Cards {
+		if pc.PlayerID != playerID {
+			continue
+		}
+		switch {
+		case i < coveredBelow:
+			total += coverFireStrength
+		case pc.FaceUp:
+			total += pc.Card.Strength
+		case escalated:
+			total += escalatedStrength
+		default:
+			total += faceDownStrength
+		}
+	}
+
+	for _, bc := range activeAbilityCards(game, models.AbilitySupport) {
+		if bc.Played.PlayerID == playerID && isAdjacent(game, bc.Theater, t) {
+			total += supportBonus
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestComputeTheaterScores(t *testing.T) {
+	type placement struct {
+		theater  models.TheaterType
+		playerID string
+		id       int
+		faceUp   bool
+	}
+	tests := []struct {
+		name   string
+		board  []placement
+		p1, p2 [3]int // Totals in Air, Land and Sea
+	}{
+		{
+			name:  "face-up cards count their strength",
+			board: []placement{{models.Air, "p1", 6, true}, {models.Sea, "p2", 17, true}},
+			p1:    [3]int{6, 0, 0},
+			p2:    [3]int{0, 0, 5},
+		},
+		{
+			name:  "face-down cards count 2",
+			board: []placement{{models.Air, "p1", 6, false}, {models.Land, "p1", 18, false}, {models.Land, "p2", 12, false}},
+			p1:    [3]int{2, 2, 0},
+			p2:    [3]int{0, 2, 0},
+		},
+		{
+			name:  "Support in an end theater strengthens its one neighbor",
+			board: []placement{{models.Air, "p1", 1, true}},
+			p1:    [3]int{1, 3, 0},
+		},
+		{
+			name:  "Support in the middle theater strengthens both ends",
+			board: []placement{{models.Land, "p1", 1, true}},
+			p1:    [3]int{3, 1, 3},
+		},
+		{
+			name:  "face-down Support does nothing",
+			board: []placement{{models.Air, "p1", 1, false}},
+			p1:    [3]int{2, 0, 0},
+		},
+		{
+			name:  "Support only strengthens its owner",
+			board: []placement{{models.Air, "p2", 1, true}, {models.Land, "p1", 12, true}},
+			p1:    [3]int{0, 6, 0},
+			p2:    [3]int{1, 3, 0},
+		},
+		{
+			name: "Escalation makes its owner's face-down cards 4",
+			board: []placement{
+				{models.Sea, "p1", 14, true},
+				{models.Air, "p1", 6, false},
+				{models.Land, "p1", 12, false},
+				{models.Air, "p2", 5, false},
+			},
+			p1: [3]int{4, 4, 2},
+			p2: [3]int{2, 0, 0},
+		},
+		{
+			name:  "face-down Escalation does nothing",
+			board: []placement{{models.Sea, "p1", 14, false}, {models.Air, "p1", 6, false}},
+			p1:    [3]int{2, 0, 2},
+		},
+		{
+			name:  "Cover Fire makes the cards it covers 4",
+			board: []placement{{models.Land, "p1", 9, true}, {models.Land, "p1", 8, false}, {models.Land, "p1", 10, true}},
+			p1:    [3]int{0, 12, 0},
+		},
+		{
+			name:  "covered Cover Fire still covers the cards beneath it",
+			board: []placement{{models.Land, "p1", 9, true}, {models.Land, "p1", 10, true}, {models.Land, "p1", 12, true}},
+			p1:    [3]int{0, 14, 0},
+		},
+		{
+			name:  "Cover Fire covered by a face-down card",
+			board: []placement{{models.Land, "p1", 9, true}, {models.Land, "p1", 10, true}, {models.Land, "p1", 11, false}},
+			p1:    [3]int{0, 10, 0},
+		},
+		{
+			name:  "Cover Fire leaves the opponent's cards alone",
+			board: []placement{{models.Land, "p2", 7, true}, {models.Land, "p1", 9, true}, {models.Land, "p1", 10, true}},
+			p1:    [3]int{0, 8, 0},
+			p2:    [3]int{0, 1, 0},
+		},
+		{
+			name:  "face-down Cover Fire does nothing",
+			board: []placement{{models.Land, "p1", 9, true}, {models.Land, "p1", 10, false}},
+			p1:    [3]int{0, 5, 0},
+		},
+		{
+			name: "Support and Escalation together",
+			board: []placement{
+				{models.Land, "p1", 1, true},
+				{models.Sea, "p1", 14, true},
+				{models.Air, "p1", 6, false},
+			},
+			p1: [3]int{7, 1, 5},
+		},
+	}
+
+	order := []models.TheaterType{models.Air, models.Land, models.Sea}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newBoard(nil, nil)
+			for _, p := range tt.board {
+				place(game, p.theater, p.playerID, p.id, p.faceUp)
+			}
+
+			scores := ComputeTheaterScores(game)
+			for i, theater := range order {
+				got := scores[theater]
+				if got.Player1Total != tt.p1[i] || got.Player2Total != tt.p2[i] {
+					t.Errorf("%s = %d-%d, want %d-%d", theater, got.Player1Total, got.Player2Total, tt.p1[i], tt.p2[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSupportFollowsTheaterOrder(t *testing.T) {
+	game := newBoard(nil, nil)
+	game.TheaterOrder = []models.TheaterType{models.Sea, models.Air, models.Land}
+	place(game, models.Air, "p1", 1, true)
+
+	scores := ComputeTheaterScores(game)
+	if scores[models.Sea].Player1Total != 3 || scores[models.Land].Player1Total != 3 {
+		t.Errorf("Support in the middle of Sea, Air, Land = %d in sea and %d in land, want 3 in both",
+			scores[models.Sea].Player1Total, scores[models.Land].Player1Total)
+	}
+}
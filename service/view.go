@@ -0,0 +1,161 @@
+package service
+
+import (
+	"time"
+
+	"github.com/dfturn/alns/models"
+)
+
+// NewGameView builds viewerID's view of a game. Viewers who are not in the
+// game see neither hand.
+func NewGameView(game *models.GameState, viewerID string) *models.GameView {
+	return buildGameView(game, viewerID, false)
+}
+
+// NewRevealedGameView builds a view of a game with both hands and every
+// face-down card shown. It is meant for reviewing games that are over.
+func NewRevealedGameView(game *models.GameState, viewerID string) *models.GameView {
+	return buildGameView(game, viewerID, true)
+}
+
+// NewRoomView returns a room without its seed, which would let anyone work
+// out the hidden cards of its games, or the credentials of its seats and
+// spectators
+func NewRoomView(room *models.Room) *models.Room {
+	view := *room
+	view.Options.Seed = nil
+	view.Seats = nil
+	view.Spectators = nil
+	view.SpectatorCount = len(room.Spectators)
+	return &view
+}
+
+func buildGameView(game *models.GameState, viewerID string, reveal bool) *models.GameView {
+	view := &models.GameView{
+		ID:                game.ID,
+		RoomID:            game.RoomID,
+		Version:           game.Version,
+		ViewerID:          viewerID,
+		Player1:           playerView(game.Player1, viewerID, reveal),
+		Player2:           playerView(game.Player2, viewerID, reveal),
+		DeckCount:         len(game.Deck),
+		TrashCount:        len(game.Trash),
+		TheaterOrder:      append([]models.TheaterType(nil), game.TheaterOrder...),
+		Theaters:          make(map[models.TheaterType]*models.Theater, len(game.Theaters)),
+		CurrentPlayerID:   game.CurrentPlayerID,
+		Phase:             game.Phase,
+		BattleNumber:      game.BattleNumber,
+		FirstPlayerID:     game.FirstPlayerID,
+		WithdrewPlayerID:  game.WithdrewPlayerID,
+		ScoreSubmissions:  cloneScoreSubmissions(game.ScoreSubmissions),
+		DisputedTheaters:  append([]models.TheaterType(nil), game.DisputedTheaters...),
+		BattleWinnerID:    game.BattleWinnerID,
+		AirDropPlayerID:   game.AirDropPlayerID,
+		AirDropReady:      game.AirDropReady,
+		ExtraTurnPlayerID: game.ExtraTurnPlayerID,
+		Mode:              game.Mode,
+		WinnerID:          game.WinnerID,
+		ForfeitedPlayerID: game.ForfeitedPlayerID,
+		LeftPlayerID:      game.LeftPlayerID,
+		Series:            cloneSeries(game.Series),
+		SeriesHistory:     cloneSeriesHistory(game.SeriesHistory),
+	}
+
+	if game.TimeControl != nil {
+		timeControl := *game.TimeControl
+		view.TimeControl = &timeControl
+	}
+	if game.Clock != nil {
+		view.Clock = remainingAt(game.Clock, time.Now())
+	}
+
+	for t, theater := range game.Theaters {
+		redacted := theaterView(*theater, viewerID, reveal)
+		view.Theaters[t] = &redacted
+	}
+
+	// Past boards are shown in full once the game is over, like the move log
+	for _, result := range cloneBattleResults(game.BattleResults) {
+		for i, theater := range result.Board {
+			result.Board[i] = theaterView(theater, viewerID, reveal || game.Phase == models.PhaseGameOver)
+		}
+		view.BattleResults = append(view.BattleResults, result)
+	}
+
+	if game.TheaterScores != nil {
+		view.TheaterScores = make(map[models.TheaterType]*models.TheaterScore, len(game.TheaterScores))
+		for t, score := range game.TheaterScores {
+			scoreCopy := *score
+			view.TheaterScores[t] = &scoreCopy
+		}
+	}
+
+	for _, pending := range game.PendingAbilities {
+		// Only the owner of Reinforce gets to look at the top of the deck
+		if pending.OwnerID != viewerID && !reveal {
+			pending.RevealedCard = nil
+		}
+		view.PendingAbilities = append(view.PendingAbilities, pending)
+	}
+
+	return view
+}
+
+// theaterView hides the face-down cards in a theater that the viewer does
+// not own, unless reveal is set
+func theaterView(theater models.Theater, viewerID string, reveal bool) models.Theater {
+	cards := make([]models.PlayedCard, len(theater.Cards))
+	for i, pc := range theater.Cards {
+		if !pc.FaceUp && pc.PlayerID != viewerID && !reveal {
+			pc.Card = models.Card{}
+		}
+		cards[i] = pc
+	}
+	return models.Theater{Type: theater.Type, Cards: cards}
+}
+
+func playerView(player models.Player, viewerID string, reveal bool) models.PlayerView {
+	view := models.PlayerView{
+		ID:        player.ID,
+		Name:      player.Name,
+		Hand:      []models.Card{},
+		HandCount: len(player.Hand),
+		Score:     player.Score,
+	}
+	if player.ID == viewerID || reveal {
+		view.Hand = append(view.Hand, player.Hand...)
+	}
+	return view
+}
+
+// NewEventLogView builds viewerID's copy of a game's move log. While the
+// game is still being played it hides the deals, the initial state and
+// which cards the other player placed face-down or picked for abilities.
+// The seed is never shown, since the next game of a series is dealt from
+// it too.
+func NewEventLogView(game *models.GameState, events []models.GameEvent, viewerID string) []models.GameEvent {
+	view := make([]models.GameEvent, len(events))
+	for i, event := range events {
+		if event.Initial != nil {
+			initial := *event.Initial
+			initial.Seed = 0
+			event.Initial = &initial
+		}
+		if game.Phase != models.PhaseGameOver {
+			event.Initial = nil
+			event.Action.Deal = nil
+			if event.Action.PlayerID != viewerID {
+				if !event.Action.FaceUp {
+					event.Action.CardID = 0
+				}
+				if event.Action.Choice != nil {
+					choice := *event.Action.Choice
+					choice.CardID = 0
+					event.Action.Choice = &choice
+				}
+			}
+		}
+		view[i] = event
+	}
+	return view
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dfturn/alns/models"
+)
+
+func TestViewsNeverShowSeed(t *testing.T) {
+	seed := int64(987654321)
+	s, game := newTestGame(t, models.RoomOptions{Seed: &seed, BestOf: 3})
+	events, err := s.GetEvents(game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The move log is revealed between games of a series, while the next
+	// game is still to be dealt from the same seed
+	over := cloneGame(game)
+	over.Phase = models.PhaseGameOver
+
+	views := map[string]any{
+		"game view":           NewGameView(game, game.Player1.ID),
+		"revealed game view":  NewRevealedGameView(over, game.Player1.ID),
+		"event log":           NewEventLogView(game, events, game.Player1.ID),
+		"game over event log": NewEventLogView(over, events, game.Player2.ID),
+	}
+	for name, view := range views {
+		data, err := json.Marshal(view)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "987654321") {
+			t.Errorf("%s shows the seed: %s", name, data)
+		}
+	}
+
+	if events[0].Initial.Seed != seed {
+		t.Errorf("the stored move log lost its seed")
+	}
+}
+
+func TestGameViewHidesOpponentHandAndDeck(t *testing.T) {
+	game := newBoard([]int{1, 2, 3}, []int{4, 5})
+
+	view := NewGameView(game, "p1")
+	if len(view.Player1.Hand) != 3 || view.Player1.HandCount != 3 {
+		t.Errorf("own hand = %d cards (count %d), want all 3", len(view.Player1.Hand), view.Player1.HandCount)
+	}
+	if len(view.Player2.Hand) != 0 || view.Player2.HandCount != 2 {
+		t.Errorf("opponent hand = %d cards (count %d), want none shown and a count of 2", len(view.Player2.Hand), view.Player2.HandCount)
+	}
+	if view.DeckCount != len(game.Deck) {
+		t.Errorf("deck count = %d, want %d", view.DeckCount, len(game.Deck))
+	}
+
+	data, err := json.Marshal(view)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"deck":`) {
+		t.Errorf("view shows the deck: %s", data)
+	}
+}
+
+func TestGameViewHidesFaceDownCardsFromNonOwner(t *testing.T) {
+	game := newBoard(nil, nil)
+	place(game, models.Air, "p1", 1, false)
+	place(game, models.Land, "p2", 8, true)
+
+	faceDown := NewGameView(game, "p2").Theaters[models.Air].Cards[0]
+	if faceDown.Card != (models.Card{}) || faceDown.FaceUp || faceDown.PlayerID != "p1" {
+		t.Errorf("opponent's face-down card = %+v, want a blank card owned by p1", faceDown)
+	}
+	if own := NewGameView(game, "p1").Theaters[models.Air].Cards[0]; own.Card.ID != 1 {
+		t.Errorf("own face-down card = %+v, want card 1", own)
+	}
+	if faceUp := NewGameView(game, "p1").Theaters[models.Land].Cards[0]; faceUp.Card.ID != 8 {
+		t.Errorf("opponent's face-up card = %+v, want card 8", faceUp)
+	}
+	if revealed := NewRevealedGameView(game, "p2").Theaters[models.Air].Cards[0]; revealed.Card.ID != 1 {
+		t.Errorf("revealed face-down card = %+v, want card 1", revealed)
+	}
+}
+
+func TestGameViewShowsReinforceCardOnlyToResolvingPlayer(t *testing.T) {
+	game := newBoard([]int{7}, []int{6})
+	mustApply(t, game, playAction("p1", 7, models.Land, true))
+	if len(game.PendingAbilities) == 0 || game.PendingAbilities[0].RevealedCard == nil {
+		t.Fatal("Reinforce did not reveal the top card")
+	}
+
+	if pending := NewGameView(game, "p1").PendingAbilities; len(pending) != 1 || pending[0].RevealedCard == nil {
+		t.Errorf("resolving player's pending abilities = %+v, want the revealed card", pending)
+	}
+	if pending := NewGameView(game, "p2").PendingAbilities; len(pending) != 1 || pending[0].RevealedCard != nil {
+		t.Errorf("opponent's pending abilities = %+v, want no revealed card", pending)
+	}
+	if pending := NewGameView(game, "").PendingAbilities; len(pending) != 1 || pending[0].RevealedCard != nil {
+		t.Errorf("spectator's pending abilities = %+v, want no revealed card", pending)
+	}
+	if game.PendingAbilities[0].RevealedCard == nil {
+		t.Error("building a view changed the game")
+	}
+}
+
+func TestGameViewHidesPastBoardsUntilGameOver(t *testing.T) {
+	game := newBoard(nil, nil)
+	game.BattleResults = []models.BattleResult{{
+		BattleNumber: 1,
+		Board: []models.Theater{{
+			Type:  models.Air,
+			Cards: []models.PlayedCard{{Card: testCard(1), PlayerID: "p1"}},
+		}},
+	}}
+
+	if card := NewGameView(game, "p2").BattleResults[0].Board[0].Cards[0]; card.Card != (models.Card{}) {
+		t.Errorf("past face-down card during the game = %+v, want it hidden", card)
+	}
+	if card := NewGameView(game, "p1").BattleResults[0].Board[0].Cards[0]; card.Card.ID != 1 {
+		t.Errorf("own past face-down card = %+v, want card 1", card)
+	}
+
+	game.Phase = models.PhaseGameOver
+	if card := NewGameView(game, "p2").BattleResults[0].Board[0].Cards[0]; card.Card.ID != 1 {
+		t.Errorf("past face-down card after the game = %+v, want card 1", card)
+	}
+	if game.BattleResults[0].Board[0].Cards[0].Card.ID != 1 {
+		t.Error("building a view changed the game")
+	}
+}
+
+func TestSpectatorGameViewShowsNoHand(t *testing.T) {
+	game := newBoard([]int{1, 2, 3}, []int{4, 5})
+	place(game, models.Sea, "p2", 13, false)
+
+	view := NewGameView(game, "")
+	for _, player := range []models.PlayerView{view.Player1, view.Player2} {
+		if len(player.Hand) != 0 || player.HandCount == 0 {
+			t.Errorf("spectator sees %s's hand as %d cards (count %d), want only a count", player.ID, len(player.Hand), player.HandCount)
+		}
+	}
+	if card := view.Theaters[models.Sea].Cards[0]; card.Card != (models.Card{}) {
+		t.Errorf("spectator sees face-down card %+v", card)
+	}
+}